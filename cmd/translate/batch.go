@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"AlfredWorkflows/internal/core/translate"
+)
+
+// defaultBatchWorkers 批量翻译默认的并发数
+const defaultBatchWorkers = 4
+
+// batchRow 批量翻译的一行结果
+type batchRow struct {
+	Line        int    `json:"line"`
+	Text        string `json:"text"`
+	Translation string `json:"translation"`
+	Provider    string `json:"provider,omitempty"`
+	SourceLang  string `json:"source_lang,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// BatchCommand 逐行翻译标准输入，按输入顺序输出:
+//
+//	batch [-format jsonl|tsv] [-workers 并发数] [-service 服务名] < 输入
+//
+// 每行一条，空行原样输出为空译文，便于与表格的列对齐
+// TSV 的列依次为 原文、译文、服务、源语言、错误，字段中的制表符和换行替换为空格
+func BatchCommand(tw *TranslateWorkflow, args []string) error {
+	flags := flag.NewFlagSet("batch", flag.ContinueOnError)
+	format := flags.String("format", "jsonl", "输出格式 jsonl/tsv")
+	workers := flags.Int("workers", defaultBatchWorkers, "并发数")
+	serviceName := flags.String("service", "", "只使用指定的翻译服务")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 || (*format != "jsonl" && *format != "tsv") {
+		return fmt.Errorf("usage: batch [-format jsonl|tsv] [-workers n] [-service name] < input")
+	}
+	if *workers < 1 {
+		*workers = 1
+	}
+
+	service, err := tw.OnlineService(*serviceName)
+	if err != nil {
+		return err
+	}
+	return tw.runBatch(service, os.Stdin, os.Stdout, *format, *workers)
+}
+
+// runBatch 用 workers 个并发翻译 r 的每一行，按输入顺序写入 w
+func (tw *TranslateWorkflow) runBatch(service translate.Service, r io.Reader, out io.Writer, format string, workers int) error {
+	jobs := make(chan batchRow)
+	done := make(chan batchRow)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range jobs {
+				done <- tw.translateRow(service, row)
+			}
+		}()
+	}
+
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- readBatchLines(r, jobs)
+		close(jobs)
+		wg.Wait()
+		close(done)
+	}()
+
+	// 结果按完成顺序返回，缓存到前面的行都已输出后再按顺序输出
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+	pending := map[int]batchRow{}
+	next := 1
+	for row := range done {
+		pending[row.Line] = row
+		for {
+			row, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			if err := writeBatchRow(w, format, row); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+	}
+	return <-readErr
+}
+
+// readBatchLines 逐行读取输入，行号从 1 开始
+func readBatchLines(r io.Reader, jobs chan<- batchRow) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	line := 0
+	for scanner.Scan() {
+		line++
+		jobs <- batchRow{Line: line, Text: strings.TrimSuffix(scanner.Text(), "\r")}
+	}
+	return scanner.Err()
+}
+
+// translateRow 翻译一行，记录实际使用的服务和源语言
+// 各行共用服务的限速器，超时从取得令牌后开始计时，排队等待不会使后面的行超时
+func (tw *TranslateWorkflow) translateRow(service translate.Service, row batchRow) batchRow {
+	if strings.TrimSpace(row.Text) == "" {
+		return row
+	}
+
+	ctx := translate.WithRequestTimeout(context.Background(), tw.Timeout())
+	results, err := service.Translate(ctx, row.Text)
+	switch {
+	case err != nil:
+		row.Error = err.Error()
+	case len(results) == 0:
+		row.Error = "翻译结果为空"
+	default:
+		row.Translation = results[0].Value
+		row.Provider = results[0].Provider
+		row.SourceLang = results[0].SourceLang
+	}
+	if row.SourceLang == "" {
+		row.SourceLang = translate.DetectLanguage(row.Text)
+	}
+	return row
+}
+
+// writeBatchRow 按格式输出一行
+func writeBatchRow(w io.Writer, format string, row batchRow) error {
+	if format == "tsv" {
+		fields := []string{row.Text, row.Translation, row.Provider, row.SourceLang, row.Error}
+		for i, field := range fields {
+			fields[i] = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ").Replace(field)
+		}
+		_, err := fmt.Fprintln(w, strings.Join(fields, "\t"))
+		return err
+	}
+
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
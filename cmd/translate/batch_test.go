@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"AlfredWorkflows/internal/core/translate"
+)
+
+// batchService 用函数实现的翻译服务，用于测试
+type batchService func(ctx context.Context, query string) ([]translate.TranslationResult, error)
+
+// Translate 实现 translate.Service 接口
+func (f batchService) Translate(ctx context.Context, query string) ([]translate.TranslationResult, error) {
+	return f(ctx, query)
+}
+
+func TestRunBatchOrder(t *testing.T) {
+	// 前面的行翻译得慢，结果按相反顺序完成，输出仍按输入顺序
+	delays := map[string]time.Duration{"one": 60 * time.Millisecond, "two": 30 * time.Millisecond, "three": 0}
+	var mu sync.Mutex
+	var finished []string
+	service := batchService(func(ctx context.Context, query string) ([]translate.TranslationResult, error) {
+		time.Sleep(delays[query])
+		mu.Lock()
+		finished = append(finished, query)
+		mu.Unlock()
+		return []translate.TranslationResult{{Value: strings.ToUpper(query), Provider: "fake", SourceLang: "en"}}, nil
+	})
+
+	tw := NewTranslateWorkflow()
+	var out strings.Builder
+	if err := tw.runBatch(service, strings.NewReader("one\ntwo\nthree\n"), &out, "tsv", 3); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+	if got := strings.Join(finished, ","); got != "three,two,one" {
+		t.Fatalf("finished order = %s, want three,two,one", got)
+	}
+	want := "one\tONE\tfake\ten\t\ntwo\tTWO\tfake\ten\t\nthree\tTHREE\tfake\ten\t\n"
+	if out.String() != want {
+		t.Errorf("runBatch() output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestRunBatchRows(t *testing.T) {
+	calls := 0
+	service := batchService(func(ctx context.Context, query string) ([]translate.TranslationResult, error) {
+		calls++
+		switch query {
+		case "fail":
+			return nil, errors.New("service\terror\nline")
+		case "multi":
+			return []translate.TranslationResult{{Value: "a\tb\r\nc\nd", Provider: "fake"}}, nil
+		}
+		return []translate.TranslationResult{{Value: "你好", Provider: "fake", SourceLang: "en"}}, nil
+	})
+
+	tests := []struct {
+		name   string
+		format string
+		input  string
+		want   string
+	}{
+		{
+			name:   "空行原样输出",
+			format: "tsv",
+			input:  "hello\n\n  \nhello\r\n",
+			want:   "hello\t你好\tfake\ten\t\n\t\t\t\t\n  \t\t\t\t\nhello\t你好\tfake\ten\t\n",
+		},
+		{
+			name:   "TSV字段中的制表符和换行替换为空格",
+			format: "tsv",
+			input:  "multi\nfail\n",
+			want:   "multi\ta b c d\tfake\ten\t\nfail\t\t\ten\tservice error line\n",
+		},
+		{
+			name:   "JSONL",
+			format: "jsonl",
+			input:  "hello\n\nfail\n",
+			want: `{"line":1,"text":"hello","translation":"你好","provider":"fake","source_lang":"en"}` + "\n" +
+				`{"line":2,"text":"","translation":""}` + "\n" +
+				`{"line":3,"text":"fail","translation":"","source_lang":"en","error":"service\terror\nline"}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			if err := NewTranslateWorkflow().runBatch(service, strings.NewReader(tt.input), &out, tt.format, 1); err != nil {
+				t.Fatalf("runBatch() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("runBatch() output = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+	// 空行不调用翻译服务
+	if calls != 6 {
+		t.Errorf("service calls = %d, want 6", calls)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"AlfredWorkflows/internal/core/translate"
+	"AlfredWorkflows/internal/platform/alfred"
+)
+
+// workflowName 工作流名称，用于缓存和数据目录
+const workflowName = "translate"
+
+// Command 子命令处理函数
+type Command func(tw *TranslateWorkflow, args []string) error
+
+// commands 可用的子命令
+var commands = map[string]Command{
+	"speak":    SpeakCommand,
+	"cache":    CacheCommand,
+	"progress": ProgressCommand,
+	"history":  HistoryCommand,
+	"file":     FileCommand,
+	"batch":    BatchCommand,
+	"usage":    UsageCommand,
+	"doctor":   DoctorCommand,
+	"dict":     DictCommand,
+}
+
+// commandEnv 设置为 1 时，只有一个参数的调用也按子命令执行，如 TRANSLATE_CMD=1 translate.bin doctor
+const commandEnv = "TRANSLATE_CMD"
+
+// LookupCommand 查找子命令，返回处理函数和剩余参数
+// Alfred 和 Raycast 都把整个查询作为一个参数传入，只有一个参数时总是按查询处理，避免与子命令同名的单词无法翻译
+// 不带参数的子命令需要设置环境变量 TRANSLATE_CMD=1
+func LookupCommand(args []string) (Command, []string, bool) {
+	if len(args) == 0 {
+		return nil, nil, false
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		return nil, nil, false
+	}
+	if len(args) == 1 && os.Getenv(commandEnv) != "1" {
+		return nil, nil, false
+	}
+	return command, args[1:], true
+}
+
+// SpeakCommand 播放发音: speak <文本>
+// 语音和音频地址从 Alfred 变量 speak_voice、speak_url 读取
+func SpeakCommand(tw *TranslateWorkflow, args []string) error {
+	text := strings.TrimSpace(strings.Join(args, " "))
+	if text == "" {
+		return fmt.Errorf("usage: speak <text>")
+	}
+
+	speaker := translate.NewSpeaker(alfred.CacheDir(workflowName), tw.Config.Speech)
+	speaker.Client = translate.HTTPClient(tw.Config.HTTP)
+	return speaker.Speak(context.Background(), translate.Speech{
+		Text:  text,
+		Voice: os.Getenv("speak_voice"),
+		URL:   os.Getenv("speak_url"),
+	})
+}
+
+// CacheCommand 管理翻译缓存: cache clear
+func CacheCommand(tw *TranslateWorkflow, args []string) error {
+	if len(args) != 1 || args[0] != "clear" {
+		return fmt.Errorf("usage: cache clear")
+	}
+
+	if err := tw.Cache().Clear(); err != nil {
+		return err
+	}
+	fmt.Println("翻译缓存已清空")
+	return nil
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+import "os/exec"
+
+// detach 非 unix 平台无需处理
+func detach(cmd *exec.Cmd) {}
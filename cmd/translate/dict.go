@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"AlfredWorkflows/internal/core/translate"
+	"AlfredWorkflows/internal/platform/alfred"
+)
+
+// dictIndexLockMaxAge 建立索引的锁文件超过该时间视为后台进程已异常退出
+const dictIndexLockMaxAge = 30 * time.Minute
+
+// dictServices 返回配置中的离线词典
+func (tw *TranslateWorkflow) dictServices() []*translate.DictService {
+	var dicts []*translate.DictService
+	for _, item := range tw.Config.Services {
+		if item.Name != "dict" || item.Path == "" {
+			continue
+		}
+		dicts = append(dicts, translate.NewDictService(tw.ResolveItem(item).Path))
+	}
+	return dicts
+}
+
+// DictCommand 管理离线词典: dict index
+// 读取词典文件建立索引，索引已是最新时跳过，查询时发现索引缺失会自动在后台运行
+func DictCommand(tw *TranslateWorkflow, args []string) error {
+	if len(args) != 1 || args[0] != "index" {
+		return fmt.Errorf("usage: dict index")
+	}
+	defer os.Remove(tw.dictIndexLock())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	for _, dict := range tw.dictServices() {
+		started := time.Now()
+		if err := dict.BuildIndex(ctx); err != nil {
+			return fmt.Errorf("dict index error: %s: %w", dict.Path, err)
+		}
+		fmt.Printf("%s  %v\n", dict.IndexPath, time.Since(started).Round(time.Millisecond))
+	}
+	return nil
+}
+
+// dictIndexLock 后台建立索引时的锁文件，避免每次查询都启动新的进程
+func (tw *TranslateWorkflow) dictIndexLock() string {
+	return filepath.Join(alfred.CacheDir(workflowName), "dict-index.lock")
+}
+
+// startDictIndex 有词典的索引未建立时启动后台进程建立索引，已有进程在运行时不重复启动
+func (tw *TranslateWorkflow) startDictIndex() {
+	ready := true
+	for _, dict := range tw.dictServices() {
+		if !dict.IndexReady() {
+			ready = false
+			break
+		}
+	}
+	if ready {
+		return
+	}
+
+	lock := tw.dictIndexLock()
+	if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) < dictIndexLockMaxAge {
+		return
+	}
+	os.Remove(lock)
+	if err := os.MkdirAll(filepath.Dir(lock), 0o755); err != nil {
+		return
+	}
+	file, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	file.Close()
+
+	execPath, err := os.Executable()
+	if err != nil {
+		os.Remove(lock)
+		return
+	}
+	cmd := exec.Command(execPath, "dict", "index")
+	cmd.Dir = filepath.Dir(execPath)
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		os.Remove(lock)
+		return
+	}
+	// 不等待后台进程退出，释放进程资源
+	_ = cmd.Process.Release()
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"AlfredWorkflows/internal/core/translate"
+	"AlfredWorkflows/internal/platform/alfred"
+)
+
+// 检查结果的状态标记
+const (
+	doctorOK      = "✓"
+	doctorWarning = "!"
+	doctorFailed  = "✗"
+)
+
+// doctorTestPhrases 测试翻译服务使用的文本，离线词典只能查单词
+var doctorTestPhrases = map[string]string{
+	"dict": "hello",
+}
+
+// defaultDoctorPhrase 测试在线服务使用的文本
+const defaultDoctorPhrase = "Hello, world"
+
+// doctorCheck 一项检查结果
+type doctorCheck struct {
+	Status string
+	Title  string
+	Detail string
+}
+
+// DoctorCommand 检查配置文件和各翻译服务:
+//
+//	doctor [-format text|alfred] [-offline]
+//
+// 显示实际加载的配置文件，检查未知字段、缺少的密钥、无效的地址和文件
+// 再向每个服务发送测试文本并显示耗时，-offline 时跳过，-format alfred 输出为 Alfred 结果项
+func DoctorCommand(tw *TranslateWorkflow, args []string) error {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	format := flags.String("format", "text", "输出格式 text/alfred")
+	offline := flags.Bool("offline", false, "不测试翻译服务")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 || (*format != "text" && *format != "alfred") {
+		return fmt.Errorf("usage: doctor [-format text|alfred] [-offline]")
+	}
+
+	checks := tw.configChecks()
+	if !*offline && tw.ConfigErr == nil {
+		checks = append(checks, tw.serviceChecks()...)
+	}
+
+	if *format == "alfred" {
+		response := alfred.NewResponse()
+		valid := false
+		for _, check := range checks {
+			response.AddItem(alfred.AlfredItem{
+				Title:    check.Status + " " + check.Title,
+				Subtitle: check.Detail,
+				Valid:    &valid,
+			})
+		}
+		response.Print()
+		return nil
+	}
+
+	failed := 0
+	for _, check := range checks {
+		line := check.Status + " " + check.Title
+		if check.Detail != "" {
+			line += "  " + check.Detail
+		}
+		fmt.Println(line)
+		if check.Status == doctorFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("doctor error: %d 项检查未通过", failed)
+	}
+	return nil
+}
+
+// configChecks 检查配置文件的位置和内容
+func (tw *TranslateWorkflow) configChecks() []doctorCheck {
+	path := tw.ConfigPath
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		detail := err.Error()
+		if errors.Is(err, os.ErrNotExist) {
+			detail = "文件不存在，请参考 config.yaml.example 创建"
+		}
+		return []doctorCheck{{Status: doctorFailed, Title: "配置文件 " + path, Detail: detail}}
+	}
+
+	checks := []doctorCheck{{Status: doctorOK, Title: "配置文件 " + path, Detail: "已加载"}}
+	if tw.ConfigErr != nil {
+		checks[0] = doctorCheck{Status: doctorFailed, Title: "配置文件 " + path, Detail: "加载失败"}
+	}
+	issues := translate.ValidateConfig(data, tw.ConfigDir)
+	for _, issue := range issues {
+		status := doctorFailed
+		if issue.Severity == translate.IssueWarning {
+			status = doctorWarning
+		}
+		title := issue.Location()
+		if title == "" {
+			title = "配置检查"
+		}
+		checks = append(checks, doctorCheck{Status: status, Title: title, Detail: issue.Message})
+	}
+	if len(issues) == 0 {
+		checks = append(checks, doctorCheck{Status: doctorOK, Title: "配置检查", Detail: "未发现问题"})
+	}
+	return checks
+}
+
+// serviceChecks 并发向每个服务发送测试文本，不使用缓存、重试和回退，按配置顺序返回结果
+// 测试请求同样计入用量，额度已用完的服务不再请求
+func (tw *TranslateWorkflow) serviceChecks() []doctorCheck {
+	usage := tw.Usage()
+	checks := make([]doctorCheck, len(tw.Config.Services))
+	var wg sync.WaitGroup
+	for i, item := range tw.Config.Services {
+		wg.Add(1)
+		go func(i int, item translate.ConfigItem) {
+			defer wg.Done()
+			checks[i] = tw.serviceCheck(item, usage)
+		}(i, item)
+	}
+	wg.Wait()
+	return checks
+}
+
+// serviceCheck 测试单个服务，显示耗时和译文
+func (tw *TranslateWorkflow) serviceCheck(item translate.ConfigItem, usage *translate.Usage) doctorCheck {
+	title := "服务 " + item.Name
+	service := translate.NewService(tw.ResolveItem(item))
+	if service == nil {
+		return doctorCheck{Status: doctorFailed, Title: title, Detail: "未启用，请检查必填字段"}
+	}
+	if !translate.IsLocal(service) {
+		service = translate.NewMeteredService(service, usage, tw.Config.UsageKey(item), item.Quota)
+	}
+
+	phrase, ok := doctorTestPhrases[item.Name]
+	if !ok {
+		phrase = defaultDoctorPhrase
+	}
+	timeout := tw.Timeout()
+	if item.Timeout > 0 {
+		timeout = time.Duration(item.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	results, err := service.Translate(ctx, phrase)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	switch {
+	case err != nil:
+		return doctorCheck{Status: doctorFailed, Title: fmt.Sprintf("%s %v", title, elapsed), Detail: err.Error()}
+	case len(results) == 0:
+		return doctorCheck{Status: doctorFailed, Title: fmt.Sprintf("%s %v", title, elapsed), Detail: "翻译结果为空"}
+	}
+	return doctorCheck{Status: doctorOK, Title: fmt.Sprintf("%s %v", title, elapsed), Detail: phrase + " → " + results[0].Title}
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"AlfredWorkflows/internal/core/translate"
+)
+
+// defaultFileBatch 每次请求合并翻译的条目数
+const defaultFileBatch = 20
+
+// fileProgressEntry 断点续传文件中一条已完成的译文，原文变化时重新翻译
+type fileProgressEntry struct {
+	Source      string `json:"source"`
+	Translation string `json:"translation"`
+}
+
+// FileCommand 翻译字幕、PO 和 JSON 国际化文件:
+//
+//	file [-o 输出文件] [-service 服务名] [-batch 条数] [-format srt|vtt|po|json] <文件|->
+//
+// 未指定输出文件时写入源文件旁的 名称.目标语言.扩展名(.pot 输出为 .po)，从标准输入读取时输出到标准输出
+// 每批完成后保存进度到输出文件旁的 .progress.json，失败后重新运行从断点继续，全部完成后删除
+func FileCommand(tw *TranslateWorkflow, args []string) error {
+	flags := flag.NewFlagSet("file", flag.ContinueOnError)
+	output := flags.String("o", "", "输出文件")
+	serviceName := flags.String("service", "", "只使用指定的翻译服务")
+	batch := flags.Int("batch", defaultFileBatch, "每次请求合并翻译的条目数")
+	format := flags.String("format", "", "文件格式 srt/vtt/po/json，默认根据扩展名或内容识别")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: file [-o output] [-service name] [-batch n] [-format srt|vtt|po|json] <file|->")
+	}
+	input := flags.Arg(0)
+
+	var data []byte
+	var err error
+	if input == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(input)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *format == "" {
+		*format = translate.DetectDocumentFormat(input, data)
+	}
+	doc, err := translate.ParseDocument(*format, data)
+	if err != nil {
+		return err
+	}
+	entries := doc.Entries()
+
+	if *output == "" && input != "-" {
+		*output = documentOutputPath(input, documentTargetLang(entries))
+	}
+
+	service, err := tw.OnlineService(*serviceName)
+	if err != nil {
+		return err
+	}
+
+	progressPath := ""
+	progress := map[string]fileProgressEntry{}
+	if *output != "" {
+		progressPath = *output + ".progress.json"
+		progress = loadFileProgress(progressPath)
+	}
+
+	translations := map[string]string{}
+	var pending []translate.DocumentEntry
+	for _, entry := range entries {
+		if strings.TrimSpace(entry.Text) == "" {
+			continue
+		}
+		if done, ok := progress[entry.ID]; ok && done.Source == entry.Text {
+			translations[entry.ID] = done.Translation
+			continue
+		}
+		pending = append(pending, entry)
+	}
+
+	total := len(translations) + len(pending)
+	for _, group := range documentBatches(pending, *batch) {
+		if err := tw.translateEntries(service, group, translations); err != nil {
+			return fmt.Errorf("translate file error: %v (已完成 %d/%d，重新运行将从断点继续)", err, len(translations), total)
+		}
+		for _, entry := range group {
+			progress[entry.ID] = fileProgressEntry{Source: entry.Text, Translation: translations[entry.ID]}
+		}
+		if progressPath != "" {
+			if err := saveFileProgress(progressPath, progress); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(os.Stderr, "已翻译 %d/%d\n", len(translations), total)
+	}
+
+	rendered := doc.Render(translations)
+	if *output == "" {
+		_, err := os.Stdout.Write(rendered)
+		return err
+	}
+	if err := os.WriteFile(*output, rendered, 0o644); err != nil {
+		return err
+	}
+	if progressPath != "" {
+		os.Remove(progressPath)
+	}
+	fmt.Printf("已翻译 %d 条，输出到 %s\n", total, *output)
+	return nil
+}
+
+// OnlineService 返回用于批量翻译的在线服务，多个服务按配置顺序回退
+// name 不为空时只使用该服务
+func (tw *TranslateWorkflow) OnlineService(name string) (translate.Service, error) {
+	scoped := *tw
+	if name != "" {
+		config := *tw.Config
+		config.Services = nil
+		config.Fallback = nil
+		for _, item := range tw.Config.Services {
+			if item.Name == name {
+				config.Services = append(config.Services, item)
+			}
+		}
+		if len(config.Services) == 0 {
+			return nil, fmt.Errorf("translate error: service %q not configured", name)
+		}
+		scoped.Config = &config
+	}
+
+	var online []translate.Service
+	for _, service := range scoped.Services() {
+		if !translate.IsLocal(service) {
+			online = append(online, service)
+		}
+	}
+	switch len(online) {
+	case 0:
+		return nil, fmt.Errorf("translate error: no online service configured")
+	case 1:
+		return online[0], nil
+	}
+	return translate.NewFallbackService(online...), nil
+}
+
+// translateEntries 翻译一批条目，格式化占位符原样保留，译文写入 translations
+func (tw *TranslateWorkflow) translateEntries(service translate.Service, entries []translate.DocumentEntry, translations map[string]string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), documentBatchTimeout(tw.Timeout(), len(entries)))
+	defer cancel()
+
+	texts := make([]string, len(entries))
+	placeholders := make([][]string, len(entries))
+	for i, entry := range entries {
+		texts[i], placeholders[i] = translate.MaskFormatPlaceholders(entry.Text)
+	}
+
+	lines, _, err := translate.TranslateLines(ctx, service, texts)
+	if err != nil {
+		return err
+	}
+	for i, entry := range entries {
+		translations[entry.ID] = translate.RestoreFormatPlaceholders(lines[i], placeholders[i])
+	}
+	return nil
+}
+
+// documentBatchTimeout 一批条目的超时，合并翻译的行数不一致时会逐条翻译，最多 n+1 次请求，每次请求(包括限速等待)按一次查询的超时计算
+func documentBatchTimeout(timeout time.Duration, n int) time.Duration {
+	if n <= 1 {
+		return timeout
+	}
+	return timeout * time.Duration(n+1)
+}
+
+// documentBatches 将条目分批，含有换行或标签的条目单独一批，避免合并后无法按行拆分
+func documentBatches(entries []translate.DocumentEntry, size int) [][]translate.DocumentEntry {
+	if size <= 0 {
+		size = defaultFileBatch
+	}
+	var batches [][]translate.DocumentEntry
+	var current []translate.DocumentEntry
+	for _, entry := range entries {
+		if strings.Contains(entry.Text, "\n") || translate.DetectMarkup(entry.Text) != "" {
+			batches = append(batches, []translate.DocumentEntry{entry})
+			continue
+		}
+		current = append(current, entry)
+		if len(current) >= size {
+			batches = append(batches, current)
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+// documentTargetLang 推测译文语言，与翻译服务一致：以中文为主时译为英文，否则译为中文
+func documentTargetLang(entries []translate.DocumentEntry) string {
+	chinese := 0
+	for _, entry := range entries {
+		if translate.HasChineseChar(entry.Text) {
+			chinese++
+		}
+	}
+	if chinese*2 > len(entries) {
+		return "en"
+	}
+	return "zh"
+}
+
+// documentOutputPath 返回源文件旁的输出路径，如 intro.srt → intro.zh.srt、messages.pot → messages.zh.po
+func documentOutputPath(path, lang string) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	if strings.EqualFold(ext, ".pot") {
+		ext = ".po"
+	}
+	return base + "." + lang + ext
+}
+
+// loadFileProgress 读取断点续传文件，不存在或损坏时从头开始
+func loadFileProgress(path string) map[string]fileProgressEntry {
+	progress := map[string]fileProgressEntry{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return progress
+	}
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return map[string]fileProgressEntry{}
+	}
+	return progress
+}
+
+// saveFileProgress 保存断点续传文件
+func saveFileProgress(path string, progress map[string]fileProgressEntry) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
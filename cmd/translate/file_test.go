@@ -0,0 +1,104 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"AlfredWorkflows/internal/core/translate"
+)
+
+func TestDocumentBatches(t *testing.T) {
+	entry := func(id, text string) translate.DocumentEntry {
+		return translate.DocumentEntry{ID: id, Text: text}
+	}
+	tests := []struct {
+		name    string
+		entries []translate.DocumentEntry
+		size    int
+		want    [][]string // 每批条目的 ID
+	}{
+		{name: "空", entries: nil, size: 2, want: nil},
+		{name: "按条数分批", entries: []translate.DocumentEntry{entry("1", "a"), entry("2", "b"), entry("3", "c")}, size: 2, want: [][]string{{"1", "2"}, {"3"}}},
+		{name: "正好整批", entries: []translate.DocumentEntry{entry("1", "a"), entry("2", "b")}, size: 2, want: [][]string{{"1", "2"}}},
+		{name: "多行条目单独一批", entries: []translate.DocumentEntry{entry("1", "a"), entry("2", "b\nc"), entry("3", "d")}, size: 5, want: [][]string{{"2"}, {"1", "3"}}},
+		{name: "含有标签的条目单独一批", entries: []translate.DocumentEntry{entry("1", "<b>a</b>"), entry("2", "b")}, size: 5, want: [][]string{{"1"}, {"2"}}},
+		{name: "条数无效时使用默认值", entries: []translate.DocumentEntry{entry("1", "a"), entry("2", "b")}, size: 0, want: [][]string{{"1", "2"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]string
+			for _, batch := range documentBatches(tt.entries, tt.size) {
+				var ids []string
+				for _, entry := range batch {
+					ids = append(ids, entry.ID)
+				}
+				got = append(got, ids)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("documentBatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocumentOutputPath(t *testing.T) {
+	tests := []struct {
+		path string
+		lang string
+		want string
+	}{
+		{path: "intro.srt", lang: "zh", want: "intro.zh.srt"},
+		{path: "/tmp/subs/intro.en.vtt", lang: "zh", want: "/tmp/subs/intro.en.zh.vtt"},
+		{path: "messages.pot", lang: "zh", want: "messages.zh.po"},
+		{path: "messages.POT", lang: "en", want: "messages.en.po"},
+		{path: "locales/zh.json", lang: "en", want: "locales/zh.en.json"},
+		{path: "README", lang: "zh", want: "README.zh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := documentOutputPath(tt.path, tt.lang); got != tt.want {
+				t.Errorf("documentOutputPath(%q, %q) = %q, want %q", tt.path, tt.lang, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocumentTargetLang(t *testing.T) {
+	tests := []struct {
+		texts []string
+		want  string
+	}{
+		{texts: []string{"Hello", "World"}, want: "zh"},
+		{texts: []string{"你好", "世界", "OK"}, want: "en"},
+		{texts: []string{"你好", "OK"}, want: "zh"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.texts, ","), func(t *testing.T) {
+			var entries []translate.DocumentEntry
+			for _, text := range tt.texts {
+				entries = append(entries, translate.DocumentEntry{Text: text})
+			}
+			if got := documentTargetLang(entries); got != tt.want {
+				t.Errorf("documentTargetLang() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocumentBatchTimeout(t *testing.T) {
+	tests := []struct {
+		n    int
+		want time.Duration
+	}{
+		{n: 1, want: 10 * time.Second},
+		// 20 条逐条翻译时每条都有完整的超时
+		{n: 20, want: 210 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := documentBatchTimeout(10*time.Second, tt.n); got != tt.want {
+			t.Errorf("documentBatchTimeout(10s, %d) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"AlfredWorkflows/internal/core/translate"
+	"AlfredWorkflows/internal/platform/alfred"
+)
+
+// defaultHistoryKeyword 默认的历史搜索关键字
+const defaultHistoryKeyword = "h:"
+
+// History 返回翻译历史
+func (tw *TranslateWorkflow) History() *translate.History {
+	return translate.NewHistory(alfred.DataDir(workflowName), tw.Config.History)
+}
+
+// HistoryQuery 判断查询是否为历史搜索，返回搜索内容
+// 空查询列出最近的记录，以关键字开头的查询搜索历史
+func (tw *TranslateWorkflow) HistoryQuery(query string) (string, bool) {
+	if tw.Config.History.Disabled {
+		return "", false
+	}
+	if strings.TrimSpace(query) == "" {
+		return "", true
+	}
+
+	keyword := tw.Config.History.Keyword
+	if keyword == "" {
+		keyword = defaultHistoryKeyword
+	}
+	trimmed := strings.TrimLeft(query, " ")
+	if strings.HasPrefix(trimmed, keyword) {
+		return strings.TrimSpace(strings.TrimPrefix(trimmed, keyword)), true
+	}
+	return "", false
+}
+
+// HistoryItems 搜索翻译历史并转换为 Alfred 结果项
+// 回车再次使用该结果，按住 cmd 键回车收藏或取消收藏
+func (tw *TranslateWorkflow) HistoryItems(search string) []alfred.AlfredItem {
+	entries, err := tw.History().Search(search)
+	if err != nil {
+		valid := false
+		return []alfred.AlfredItem{{
+			Title:    "读取翻译历史失败",
+			Subtitle: err.Error(),
+			Valid:    &valid,
+		}}
+	}
+
+	var items []alfred.AlfredItem
+	for _, entry := range entries {
+		subtitle := fmt.Sprintf("%s · %s · %s", entry.Query, entry.Provider, entry.Time.Format("2006-01-02 15:04"))
+		if entry.Count > 1 {
+			subtitle += fmt.Sprintf(" · %d次", entry.Count)
+		}
+		starAction, starSubtitle := "star", "☆ 收藏: "+entry.Query
+		if entry.Starred {
+			subtitle = "★ " + subtitle
+			starAction, starSubtitle = "unstar", "★ 取消收藏: "+entry.Query
+		}
+
+		items = append(items, alfred.AlfredItem{
+			Title:     entry.Result,
+			Subtitle:  subtitle,
+			Arg:       entry.Result,
+			Variables: historyVariables(entry.Query, entry.Provider),
+			Mods: map[string]alfred.AlfredMod{
+				"cmd": {
+					Arg:      entry.ID,
+					Subtitle: starSubtitle,
+					Variables: map[string]string{
+						"action": starAction,
+					},
+				},
+			},
+		})
+	}
+	return items
+}
+
+// historyVariables 回车时记录翻译历史所需的 Alfred 变量
+func historyVariables(query, provider string) map[string]string {
+	return map[string]string{
+		"action":           "record",
+		"history_query":    query,
+		"history_provider": provider,
+	}
+}
+
+// HistoryCommand 管理翻译历史:
+//
+//	history record <译文>          记录翻译，原文和服务从 Alfred 变量 history_query、history_provider 读取，并原样输出译文
+//	history star|unstar <记录ID>   收藏或取消收藏
+//	history export csv|anki [文件] 导出收藏的记录，未指定文件时输出到标准输出
+//	history clear                  清空未收藏的记录
+func HistoryCommand(tw *TranslateWorkflow, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: history record|star|unstar|export|clear")
+	}
+	history := tw.History()
+
+	switch args[0] {
+	case "record":
+		result := strings.Join(args[1:], " ")
+		if !tw.Config.History.Disabled && strings.TrimSpace(result) != "" {
+			if _, err := history.Add(os.Getenv("history_query"), result, os.Getenv("history_provider")); err != nil {
+				return err
+			}
+		}
+		// 输出译文供后续的复制、粘贴动作使用
+		fmt.Print(result)
+		return nil
+	case "star", "unstar":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: history %s <id>", args[0])
+		}
+		if err := history.Star(args[1], args[0] == "star"); err != nil {
+			return err
+		}
+		if args[0] == "star" {
+			fmt.Println("已收藏")
+		} else {
+			fmt.Println("已取消收藏")
+		}
+		return nil
+	case "export":
+		return exportHistory(history, args[1:])
+	case "clear":
+		if err := history.Clear(); err != nil {
+			return err
+		}
+		fmt.Println("翻译历史已清空，收藏的记录已保留")
+		return nil
+	}
+	return fmt.Errorf("usage: history record|star|unstar|export|clear")
+}
+
+// exportHistory 导出收藏的记录: export csv|anki [文件]
+func exportHistory(history *translate.History, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return fmt.Errorf("usage: history export csv|anki [file]")
+	}
+
+	var export func(io.Writer, []translate.HistoryEntry) error
+	switch args[0] {
+	case "csv":
+		export = translate.ExportCSV
+	case "anki":
+		export = translate.ExportAnki
+	default:
+		return fmt.Errorf("usage: history export csv|anki [file]")
+	}
+
+	entries, err := history.Starred()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		return export(os.Stdout, entries)
+	}
+
+	file, err := os.Create(args[1])
+	if err != nil {
+		return err
+	}
+	if err := export(file, entries); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("已导出 %d 条收藏到 %s\n", len(entries), args[1])
+	return nil
+}
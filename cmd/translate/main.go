@@ -6,7 +6,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"sync"
+	"strings"
 	"time"
 
 	"AlfredWorkflows/internal/core/translate"
@@ -18,8 +18,21 @@ import (
 
 // TranslateWorkflow 翻译工作流结构体
 type TranslateWorkflow struct {
-	Config   *translate.Config
-	Workflow *alfred.AlfredWorkflow
+	Config     *translate.Config
+	Workflow   *alfred.AlfredWorkflow
+	NoCache    bool   // 跳过翻译缓存
+	ConfigDir  string // 配置文件所在目录，配置中的相对路径以此为基准
+	ConfigPath string // 实际加载的配置文件
+	ConfigErr  error  // 加载配置文件时的错误，Alfred 中提示运行 doctor 检查
+}
+
+// serviceOutcome 单个翻译服务的返回结果
+type serviceOutcome struct {
+	index   int
+	results []translate.TranslationResult
+	err     error
+	pending bool // 超时前未返回
+	local   bool // 本地服务(离线词典)
 }
 
 // NewTranslateWorkflow 创建新的翻译工作流
@@ -47,6 +60,8 @@ func (tw *TranslateWorkflow) LoadConfig(path string) error {
 		path = filepath.Join(filepath.Dir(execPath), "config.yaml")
 	}
 
+	tw.ConfigPath = path
+	tw.ConfigDir = filepath.Dir(path)
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return err
@@ -55,10 +70,215 @@ func (tw *TranslateWorkflow) LoadConfig(path string) error {
 	return yaml.Unmarshal(data, tw.Config)
 }
 
+// Cache 返回翻译缓存
+func (tw *TranslateWorkflow) Cache() *translate.Cache {
+	return translate.NewCache(filepath.Join(alfred.CacheDir(workflowName), "translations"), tw.Config.Cache)
+}
+
+// Glossary 加载术语表，术语表文件无法读取时只使用配置中的术语
+func (tw *TranslateWorkflow) Glossary() *translate.Glossary {
+	glossary, err := translate.LoadGlossary(tw.Config.Glossary, tw.ConfigDir)
+	if err != nil {
+		log.Printf("加载术语表失败: %v", err)
+		config := tw.Config.Glossary
+		config.File = ""
+		glossary, _ = translate.LoadGlossary(config, tw.ConfigDir)
+	}
+	return glossary
+}
+
+// Services 根据配置创建所有可用的翻译服务
+// 每个服务依次包装 用量统计 → 限速 → 分段 → 术语表 → 标记识别 → 超时重试 → 熔断 → 缓存 → 剩余额度 → 敏感信息检查，回退链中的服务合并为一个按顺序尝试的服务
+func (tw *TranslateWorkflow) Services() []translate.Service {
+	// 离线词典的索引在后台进程中建立，建立完成前词典返回提示
+	tw.startDictIndex()
+
+	useCache := !tw.NoCache && !tw.Config.Cache.Disabled
+	cache := tw.Cache()
+	glossary := tw.Glossary()
+	guard := translate.NewPrivacyGuard(tw.Config.Privacy)
+	usage := tw.Usage()
+	breaker := translate.NewBreaker(filepath.Join(alfred.CacheDir(workflowName), "breaker.json"), tw.Config.CircuitBreaker)
+
+	// 回退链中各服务名称的顺序
+	fallbackOrder := map[string]int{}
+	for i, name := range tw.Config.Fallback {
+		if _, ok := fallbackOrder[name]; !ok {
+			fallbackOrder[name] = i
+		}
+	}
+
+	var services []translate.Service
+	fallbackIndex := -1
+	fallbackServices := make([][]translate.Service, len(tw.Config.Fallback))
+	for _, item := range tw.Config.Services {
+		// 用量名称按配置文件中的原始值计算，与 usage 命令一致
+		usageKey := tw.Config.UsageKey(item)
+		item = tw.ResolveItem(item)
+		service := translate.NewService(item)
+		if service == nil {
+			continue
+		}
+
+		// 本地服务直接返回结果，不需要重试、熔断和缓存
+		if translate.IsLocal(service) {
+			services = append(services, service)
+			continue
+		}
+
+		instance := item.InstanceKey()
+		service = translate.NewMeteredService(service, usage, usageKey, item.Quota)
+		if rate, burst := item.RateLimits(); rate > 0 {
+			service = translate.NewRateLimitedService(service, translate.NewRateLimiter(rate, burst))
+		}
+		service = translate.NewChunkedService(service, item.MaxChars())
+		if !glossary.Empty() {
+			service = translate.NewGlossaryService(service, glossary)
+			instance += ":" + glossary.Key()
+		}
+		if tw.Config.Markup != translate.MarkupOff {
+			service = translate.NewMarkupService(service, tw.Config.Markup)
+		}
+		service = translate.NewRetryService(service, time.Duration(item.Timeout)*time.Second, item.Retries)
+		service = translate.NewBreakerService(service, breaker, instance)
+		if useCache {
+			service = translate.NewCachedService(service, cache, instance)
+		}
+		if !item.Quota.Empty() {
+			service = translate.NewQuotaNoteService(service, usage, usageKey, item.Quota)
+		}
+		// 最后检查敏感信息，缓存中只保存替换后的查询
+		if guard != nil {
+			service = translate.NewPrivacyService(service, guard)
+		}
+
+		// 回退链放在其第一个服务所在的位置
+		if order, ok := fallbackOrder[item.Name]; ok {
+			if fallbackIndex < 0 {
+				fallbackIndex = len(services)
+				services = append(services, nil)
+			}
+			fallbackServices[order] = append(fallbackServices[order], service)
+			continue
+		}
+		services = append(services, service)
+	}
+
+	if fallbackIndex >= 0 {
+		var chain []translate.Service
+		for _, group := range fallbackServices {
+			chain = append(chain, group...)
+		}
+		services[fallbackIndex] = translate.NewFallbackService(chain...)
+	}
+	return services
+}
+
+// ResolveItem 返回补全全局 HTTP 设置、相对路径转为绝对路径并设置状态目录后的服务配置
+func (tw *TranslateWorkflow) ResolveItem(item translate.ConfigItem) translate.ConfigItem {
+	item.HTTP = item.HTTP.WithDefaults(tw.Config.HTTP)
+	if item.Path != "" && !filepath.IsAbs(item.Path) {
+		item.Path = filepath.Join(tw.ConfigDir, item.Path)
+	}
+	item.StateDir = alfred.CacheDir(workflowName)
+	return item
+}
+
+// ParseFlags 解析并移除参数中的选项，Alfred 传入的查询以选项开头时同样生效
+func (tw *TranslateWorkflow) ParseFlags(args []string) []string {
+	var rest []string
+	for _, arg := range args {
+		switch {
+		case arg == "--no-cache":
+			tw.NoCache = true
+		case strings.HasPrefix(arg, "--no-cache "):
+			tw.NoCache = true
+			rest = append(rest, strings.TrimPrefix(arg, "--no-cache "))
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return rest
+}
+
+// NewItem 将查询的翻译结果转换为 Alfred 结果项，回车时记录到翻译历史，包含敏感信息的查询不记录
+// 可朗读的结果按住 alt 键播放发音，中文查询的英文结果按住 ctrl 键使用 camelCase 变量名
+func (tw *TranslateWorkflow) NewItem(query string, result translate.TranslationResult) alfred.AlfredItem {
+	u := ""
+	if result.Url != nil {
+		u = *result.Url
+	}
+	item := alfred.AlfredItem{
+		Title:        result.Title,
+		Subtitle:     result.Subtitle,
+		Arg:          result.Value,
+		Quicklookurl: u,
+	}
+	if !tw.Config.History.Disabled && len(translate.NewPrivacyGuard(tw.Config.Privacy).Scan(query)) == 0 {
+		item.Variables = historyVariables(query, result.Provider)
+	}
+
+	// 没有在线音频的服务在配置了本地TTS时也可以朗读
+	speech := result.Speech
+	if speech == nil && tw.Config.Speech.TTS != "" {
+		speech = &translate.Speech{Text: result.Value}
+	}
+	if mod, ok := tw.namingMod(query, result.Value); ok {
+		item.Mods = map[string]alfred.AlfredMod{"ctrl": mod}
+	}
+	if speech != nil {
+		if item.Mods == nil {
+			item.Mods = map[string]alfred.AlfredMod{}
+		}
+		item.Mods["alt"] = alfred.AlfredMod{
+			Arg:      speech.Text,
+			Subtitle: "🔊 朗读: " + speech.Text,
+			Variables: map[string]string{
+				"action":      "speak",
+				"speak_voice": speech.Voice,
+				"speak_url":   speech.URL,
+			},
+		}
+	}
+	return item
+}
+
 // Execute 执行翻译
 func (tw *TranslateWorkflow) Execute() *alfred.AlfredResponse {
 	query := tw.GetInputQuery()
 
+	// 配置文件有误时 Alfred 看不到日志，直接提示
+	if tw.ConfigErr != nil {
+		valid := false
+		tw.Workflow.Items = []alfred.AlfredItem{{
+			Title:    "配置文件加载失败",
+			Subtitle: tw.ConfigErr.Error() + "，运行 TRANSLATE_CMD=1 translate.bin doctor 查看详情",
+			Valid:    &valid,
+		}}
+		return tw.Workflow.GetResponse()
+	}
+
+	// 以命名关键字开头时生成变量名
+	if text, ok := tw.NamingQuery(query); ok {
+		return tw.ExecuteNaming(text)
+	}
+
+	// 空查询列出最近的翻译历史，以关键字开头时搜索历史
+	if search, ok := tw.HistoryQuery(query); ok {
+		tw.Workflow.Items = tw.HistoryItems(search)
+		if len(tw.Workflow.Items) == 0 && !utils.IsEmpty(query) {
+			valid := false
+			tw.Workflow.Items = append(tw.Workflow.Items, alfred.AlfredItem{
+				Title:    "没有找到翻译历史",
+				Subtitle: "回车使用翻译结果后会记录到历史",
+				Valid:    &valid,
+			})
+		}
+		if len(tw.Workflow.Items) > 0 {
+			return tw.Workflow.GetResponse()
+		}
+	}
+
 	if utils.IsEmpty(query) {
 		item := alfred.AlfredItem{
 			Title:    "请输入要翻译的文本",
@@ -69,104 +289,102 @@ func (tw *TranslateWorkflow) Execute() *alfred.AlfredResponse {
 		return tw.Workflow.GetResponse()
 	}
 
-	// 创建上下文，设置超时
+	// 包含敏感信息且设置为阻止时不翻译
+	if item, ok := tw.PrivacyItem(query); ok && tw.Config.Privacy.Action == translate.PrivacyBlock {
+		tw.Workflow.Items = []alfred.AlfredItem{item}
+		return tw.Workflow.GetResponse()
+	}
+
+	// Alfred 中先返回已完成的结果，其余结果通过 rerun 逐步显示
+	if tw.Progressive() {
+		return tw.ExecuteProgressive(query)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tw.Timeout())
+	defer cancel()
+
+	outcomes, timeoutOccurred := tw.Collect(ctx, tw.Services(), query)
+	tw.Workflow.Items = tw.Items(outcomes, query, timeoutOccurred)
+	return tw.Workflow.GetResponse()
+}
+
+// Timeout 返回整体翻译超时时间
+func (tw *TranslateWorkflow) Timeout() time.Duration {
 	timeout := time.Duration(tw.Config.Timeout) * time.Second
 	if timeout == 0 {
 		timeout = 10 * time.Second
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+	return timeout
+}
 
-	// 创建结果通道
-	resultChan := make(chan alfred.AlfredItem, 10)
-	var wg sync.WaitGroup
-
-	// 查询有道翻译
-	wg.Add(1)
-	go func(ctx context.Context, itemChan chan<- alfred.AlfredItem) {
-		defer wg.Done()
-		youdaoConfig := tw.Config.GetConfigItemWithName("youdao")
-		if youdaoConfig != nil && youdaoConfig.AppKey != "" && youdaoConfig.AppSecret != "" {
-			service := translate.NewYoudaoService(youdaoConfig.AppKey, youdaoConfig.AppSecret)
+// Collect 并发查询所有翻译服务，按服务顺序返回结果，等待所有翻译完成或超时
+// onOutcome 不为空时每个服务返回后立即回调
+func (tw *TranslateWorkflow) Collect(ctx context.Context, services []translate.Service, query string, onOutcome ...func(serviceOutcome)) ([]serviceOutcome, bool) {
+	outcomeChan := make(chan serviceOutcome, len(services))
+	for i, service := range services {
+		go func(i int, service translate.Service) {
 			results, err := service.Translate(ctx, query)
-			if err == nil {
-				for _, result := range results {
-					u := ""
-					if result.Url != nil {
-						u = *result.Url
-					}
-					item := alfred.AlfredItem{
-						Title:        result.Title,
-						Subtitle:     result.Subtitle,
-						Arg:          result.Value,
-						Quicklookurl: u,
-					}
-					// 发送结果到通道，同时检查上下文是否已取消
-					select {
-					case itemChan <- item:
-					case <-ctx.Done():
-						return
-					}
-				}
-			}
-		}
-	}(ctx, resultChan)
+			outcomeChan <- serviceOutcome{index: i, results: results, err: err, local: translate.IsLocal(service)}
+		}(i, service)
+	}
 
-	// 查询DeepLX翻译
-	wg.Add(1)
-	go func(ctx context.Context, itemChan chan<- alfred.AlfredItem) {
-		defer wg.Done()
-		deeplxConfig := tw.Config.GetConfigItemWithName("deeplx")
-		if deeplxConfig != nil && deeplxConfig.URL != "" {
-			service := translate.NewDeeplxService(deeplxConfig.URL, deeplxConfig.Token)
-			results, err := service.Translate(ctx, query)
-			if err == nil {
-				for _, result := range results {
-					item := alfred.AlfredItem{
-						Title:    result.Title,
-						Subtitle: result.Subtitle,
-						Arg:      result.Value,
-					}
-					// 发送结果到通道，同时检查上下文是否已取消
-					select {
-					case itemChan <- item:
-					case <-ctx.Done():
-						return
-					}
-				}
+	outcomes := make([]serviceOutcome, len(services))
+	for i := range outcomes {
+		outcomes[i].index = i
+		outcomes[i].pending = true
+		outcomes[i].local = translate.IsLocal(services[i])
+	}
+
+	timeoutOccurred := false
+collect:
+	for pending := len(services); pending > 0; pending-- {
+		select {
+		case outcome := <-outcomeChan:
+			outcomes[outcome.index] = outcome
+			for _, callback := range onOutcome {
+				callback(outcome)
 			}
+		case <-ctx.Done():
+			timeoutOccurred = true
+			break collect
 		}
-	}(ctx, resultChan)
+	}
+	return outcomes, timeoutOccurred
+}
 
-	// 创建一个通道用于通知所有goroutine已完成
-	done := make(chan struct{})
-	go func() {
-		wg.Wait()
-		close(resultChan)
-		done <- struct{}{}
-	}()
+// MergeOutcomes 合并相同译文，按一致的服务数量和服务优先级排序
+// 本地词典的结果始终在前，在线服务的结果随后补充
+func MergeOutcomes(outcomes []serviceOutcome, query string) []translate.TranslationResult {
+	var localGroups, onlineGroups [][]translate.TranslationResult
+	for _, outcome := range outcomes {
+		if outcome.local {
+			localGroups = append(localGroups, outcome.results)
+		} else {
+			onlineGroups = append(onlineGroups, outcome.results)
+		}
+	}
+	return append(translate.MergeResults(localGroups, query), translate.MergeResults(onlineGroups, query)...)
+}
 
-	// 收集结果
+// Items 合并各服务的结果并转换为 Alfred 结果项，没有结果时返回错误提示
+func (tw *TranslateWorkflow) Items(outcomes []serviceOutcome, query string, timeoutOccurred bool) []alfred.AlfredItem {
 	var allItems []alfred.AlfredItem
-	timeoutOccurred := false
-
-	// 等待所有翻译完成或超时
-	select {
-	case <-done: // 所有翻译正常完成
-	case <-ctx.Done(): // 超时
-		timeoutOccurred = true
+	for _, result := range MergeOutcomes(outcomes, query) {
+		allItems = append(allItems, tw.NewItem(query, result))
 	}
 
-	// 从通道中获取所有结果
-	for item := range resultChan {
-		allItems = append(allItems, item)
+	// 如果没有结果，每个失败的服务显示一项错误信息
+	if len(allItems) == 0 {
+		allItems = append(allItems, tw.ErrorItems(outcomes)...)
+	}
+	if item, ok := tw.PrivacyItem(query); ok {
+		allItems = append([]alfred.AlfredItem{item}, allItems...)
 	}
 
-	// 如果没有结果，显示错误信息
 	if len(allItems) == 0 {
 		if timeoutOccurred {
 			allItems = append(allItems, alfred.AlfredItem{
-				Title:    fmt.Sprintf("翻译超时 %d秒", int(timeout.Seconds())),
+				Title:    fmt.Sprintf("翻译超时 %d秒", int(tw.Timeout().Seconds())),
 				Subtitle: "请检查网络连接或稍后重试",
 				Arg:      "",
 			})
@@ -178,22 +396,66 @@ func (tw *TranslateWorkflow) Execute() *alfred.AlfredResponse {
 			})
 		}
 	}
+	return allItems
+}
 
-	tw.Workflow.Items = allItems
-	return tw.Workflow.GetResponse()
+// ErrorItems 为每个失败的服务生成一项错误信息
+func (tw *TranslateWorkflow) ErrorItems(outcomes []serviceOutcome) []alfred.AlfredItem {
+	var items []alfred.AlfredItem
+	valid := false
+	for _, outcome := range outcomes {
+		for _, e := range translate.Errors(outcome.err) {
+			// 敏感信息由 PrivacyItem 统一提示
+			if e.Kind == translate.ErrorPrivacy {
+				continue
+			}
+			title := e.Kind.String()
+			if e.Provider != "" {
+				title = e.Provider + ": " + title
+			}
+			subtitle := e.Message
+			if e.Code != "" {
+				subtitle += " (" + e.Code + ")"
+			}
+			items = append(items, alfred.AlfredItem{
+				Title:    title,
+				Subtitle: subtitle,
+				Valid:    &valid,
+			})
+		}
+	}
+	return items
 }
 
 func main() {
+	// 日志中隐藏从环境变量、文件和命令读取的密钥
+	log.SetOutput(translate.NewRedactWriter(os.Stderr))
+
 	tw := NewTranslateWorkflow()
 
 	// 加载配置文件
 	configPath := filepath.Join(filepath.Dir(os.Args[0]), "config.yaml")
 	if err := tw.LoadConfig(configPath); err != nil {
+		tw.ConfigErr = err
 		log.Printf("加载配置文件失败: %v", err)
 	}
 
-	// 处理命令行参数
-	tw.Workflow.Query(os.Args[1:])
+	args := tw.ParseFlags(os.Args[1:])
+
+	// 执行子命令
+	if command, args, ok := LookupCommand(args); ok {
+		if err := command(tw, args); err != nil {
+			log.Printf("执行命令失败: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// 处理命令行参数，参数只有选项时按空查询处理
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	tw.Workflow.Query(args)
 
 	// 执行翻译并输出结果
 	response := tw.Execute()
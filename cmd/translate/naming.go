@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"AlfredWorkflows/internal/core/translate"
+	"AlfredWorkflows/internal/platform/alfred"
+)
+
+const (
+	// defaultNamingKeyword 默认的变量命名关键字
+	defaultNamingKeyword = "n:"
+	// namingCandidateLimit 生成变量名的译文数量上限
+	namingCandidateLimit = 2
+)
+
+// NamingQuery 判断查询是否为变量命名模式，返回要命名的文本
+func (tw *TranslateWorkflow) NamingQuery(query string) (string, bool) {
+	if tw.Config.Naming.Disabled {
+		return "", false
+	}
+	keyword := tw.Config.Naming.Keyword
+	if keyword == "" {
+		keyword = defaultNamingKeyword
+	}
+	trimmed := strings.TrimLeft(query, " ")
+	if !strings.HasPrefix(trimmed, keyword) {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(trimmed, keyword)), true
+}
+
+// ExecuteNaming 变量命名模式，中文先翻译为英文，每种命名风格一项
+// 英文文本直接转换，不调用翻译服务
+func (tw *TranslateWorkflow) ExecuteNaming(text string) *alfred.AlfredResponse {
+	valid := false
+	if text == "" {
+		tw.Workflow.Items = []alfred.AlfredItem{{
+			Title:    "请输入要命名的内容",
+			Subtitle: "中文翻译后生成 camelCase、PascalCase、snake_case、kebab-case、CONSTANT_CASE",
+			Valid:    &valid,
+		}}
+		return tw.Workflow.GetResponse()
+	}
+
+	candidates := []string{text}
+	var outcomes []serviceOutcome
+	if translate.HasChineseChar(text) {
+		ctx, cancel := context.WithTimeout(context.Background(), tw.Timeout())
+		defer cancel()
+
+		outcomes, _ = tw.Collect(ctx, tw.Services(), text)
+		candidates = nil
+		for _, result := range MergeOutcomes(outcomes, text) {
+			candidates = append(candidates, result.Value)
+		}
+	}
+
+	namer := translate.NewNamer(tw.Config.Naming)
+	var items []alfred.AlfredItem
+	seen := map[string]bool{}
+	for _, candidate := range candidates {
+		forms := namer.Forms(candidate)
+		if len(forms) == 0 || seen[forms[0].Value] {
+			continue
+		}
+		seen[forms[0].Value] = true
+		for _, form := range forms {
+			items = append(items, alfred.AlfredItem{
+				Title:    form.Value,
+				Subtitle: form.Style + ": " + candidate,
+				Arg:      form.Value,
+			})
+		}
+		if len(seen) >= namingCandidateLimit {
+			break
+		}
+	}
+
+	if len(items) == 0 {
+		items = tw.ErrorItems(outcomes)
+	}
+	if len(items) == 0 {
+		items = append(items, alfred.AlfredItem{
+			Title:    "无法生成变量名",
+			Subtitle: "翻译结果中没有可用的英文",
+			Valid:    &valid,
+		})
+	}
+	tw.Workflow.Items = items
+	return tw.Workflow.GetResponse()
+}
+
+// namingMod 中文查询的英文结果按住 ctrl 键回车使用 camelCase 变量名
+// 设置 action=copy 覆盖结果项记录历史的动作，只输出变量名
+func (tw *TranslateWorkflow) namingMod(query, value string) (alfred.AlfredMod, bool) {
+	if tw.Config.Naming.Disabled || !translate.HasChineseChar(query) {
+		return alfred.AlfredMod{}, false
+	}
+	forms := translate.NewNamer(tw.Config.Naming).Forms(value)
+	if len(forms) == 0 {
+		return alfred.AlfredMod{}, false
+	}
+	return alfred.AlfredMod{
+		Arg:       forms[0].Value,
+		Subtitle:  forms[0].Style + ": " + forms[0].Value,
+		Variables: map[string]string{"action": "copy"},
+	}, true
+}
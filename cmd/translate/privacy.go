@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+
+	"AlfredWorkflows/internal/core/translate"
+	"AlfredWorkflows/internal/platform/alfred"
+)
+
+// privacyActionHints 各处理方式的说明
+var privacyActionHints = map[string]string{
+	translate.PrivacyRedact: "已替换为占位符后发送到在线服务，译文中已还原",
+	translate.PrivacyLocal:  "未发送到在线服务，只使用本地词典",
+	translate.PrivacyBlock:  "未翻译，可在 config.yaml 的 privacy 中调整处理方式或添加 allow",
+}
+
+// PrivacyItem 查询包含敏感信息时的提示项，说明发现的内容和处理方式
+func (tw *TranslateWorkflow) PrivacyItem(query string) (alfred.AlfredItem, bool) {
+	guard := translate.NewPrivacyGuard(tw.Config.Privacy)
+	findings := guard.Scan(query)
+	if len(findings) == 0 {
+		return alfred.AlfredItem{}, false
+	}
+
+	var samples []string
+	for _, f := range findings {
+		samples = append(samples, maskSample(f.Text))
+	}
+	valid := false
+	return alfred.AlfredItem{
+		Title:    "⚠️ 查询包含" + strings.Join(translate.PrivacyKinds(findings), "、") + ": " + strings.Join(samples, " "),
+		Subtitle: privacyActionHints[guard.Action],
+		Valid:    &valid,
+	}, true
+}
+
+// maskSample 只显示敏感信息的首尾字符
+func maskSample(text string) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= 6 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:3]) + "…" + string(runes[len(runes)-2:])
+}
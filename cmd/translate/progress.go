@@ -0,0 +1,359 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"AlfredWorkflows/internal/core/translate"
+	"AlfredWorkflows/internal/platform/alfred"
+)
+
+const (
+	// defaultProgressBudget 首次返回前默认最多等待的时间
+	defaultProgressBudget = 400 * time.Millisecond
+	// defaultProgressRerun Alfred 默认重新读取结果的间隔 秒
+	defaultProgressRerun = 0.2
+	// progressPollInterval 等待时读取会话文件的间隔
+	progressPollInterval = 30 * time.Millisecond
+	// progressSessionVar 保存会话ID的 Alfred 变量，rerun 时以环境变量传回
+	progressSessionVar = "translate_session"
+	// progressSessionMaxAge 会话文件保留时间
+	progressSessionMaxAge = time.Hour
+	// progressCurrentFile 记录最新会话ID的文件，查询变化后旧会话的后台进程据此停止
+	progressCurrentFile = "current"
+)
+
+// progressOutcome 会话中单个服务的结果
+type progressOutcome struct {
+	Results []translate.TranslationResult `json:"results,omitempty"`
+	Errors  []*translate.Error            `json:"errors,omitempty"`
+	Done    bool                          `json:"done"`
+	Local   bool                          `json:"local,omitempty"`
+}
+
+// progressSession 后台翻译会话，由后台进程写入、Alfred 每次 rerun 读取
+// Outcomes 由后台进程创建翻译服务后按服务数量填充，为空表示后台进程尚未开始翻译
+type progressSession struct {
+	Query    string            `json:"query"`
+	Started  time.Time         `json:"started"`
+	PID      int               `json:"pid,omitempty"`
+	Outcomes []progressOutcome `json:"outcomes"`
+	Finished bool              `json:"finished"`
+	TimedOut bool              `json:"timed_out"`
+}
+
+// Progressive 是否逐步显示结果，仅在 Alfred 中生效
+func (tw *TranslateWorkflow) Progressive() bool {
+	return alfred.IsAlfred() && !tw.Config.Progress.Disabled
+}
+
+// ExecuteProgressive 在后台进程中翻译，返回预算时间内已完成的结果，未完成时设置 rerun 让 Alfred 继续读取
+func (tw *TranslateWorkflow) ExecuteProgressive(query string) *alfred.AlfredResponse {
+	budget := time.Duration(tw.Config.Progress.Budget) * time.Millisecond
+	if budget <= 0 {
+		budget = defaultProgressBudget
+	}
+	rerun := tw.Config.Progress.Rerun
+	if rerun <= 0 {
+		rerun = defaultProgressRerun
+	}
+
+	// rerun 时复用已有会话，查询变化时开始新的会话
+	sessionID := os.Getenv(progressSessionVar)
+	session, err := tw.loadSession(sessionID)
+	if err != nil || session.Query != query {
+		sessionID, session, err = tw.startSession(query)
+		if err != nil {
+			// 无法启动后台进程时退回到阻塞查询
+			ctx, cancel := context.WithTimeout(context.Background(), tw.Timeout())
+			defer cancel()
+			outcomes, timeoutOccurred := tw.Collect(ctx, tw.Services(), query)
+			tw.Workflow.Items = tw.Items(outcomes, query, timeoutOccurred)
+			return tw.Workflow.GetResponse()
+		}
+
+		// 首次执行时等待预算时间，尽量直接返回较快的结果
+		deadline := time.Now().Add(budget)
+		for !session.complete() && time.Now().Before(deadline) {
+			time.Sleep(progressPollInterval)
+			if latest, err := tw.loadSession(sessionID); err == nil {
+				session = latest
+			}
+		}
+	}
+
+	// 后台进程异常退出时按超时处理
+	if !session.Finished && time.Since(session.Started) > tw.Timeout()+2*time.Second {
+		session.Finished = true
+		session.TimedOut = true
+	}
+
+	outcomes := session.outcomes()
+	if session.complete() {
+		tw.Workflow.Items = tw.Items(outcomes, query, session.TimedOut)
+		return tw.Workflow.GetResponse()
+	}
+
+	items := tw.PartialItems(outcomes, query)
+	tw.Workflow.Items = items
+	resp := tw.Workflow.GetResponse()
+	resp.Rerun = rerun
+	resp.Variables = map[string]string{progressSessionVar: sessionID}
+	return resp
+}
+
+// PartialItems 转换已完成的结果，并在末尾提示仍在翻译的服务数量
+func (tw *TranslateWorkflow) PartialItems(outcomes []serviceOutcome, query string) []alfred.AlfredItem {
+	pending := 0
+	for _, outcome := range outcomes {
+		if outcome.pending {
+			pending++
+		}
+	}
+
+	var items []alfred.AlfredItem
+	if item, ok := tw.PrivacyItem(query); ok {
+		items = append(items, item)
+	}
+	for _, result := range MergeOutcomes(outcomes, query) {
+		items = append(items, tw.NewItem(query, result))
+	}
+
+	subtitle := "等待翻译服务返回"
+	if pending > 0 {
+		subtitle = fmt.Sprintf("等待 %d 个翻译服务返回", pending)
+	}
+	valid := false
+	items = append(items, alfred.AlfredItem{
+		Title:    "翻译中...",
+		Subtitle: subtitle,
+		Valid:    &valid,
+	})
+	return items
+}
+
+// ProgressCommand 后台翻译进程: progress <会话ID>
+// 由 ExecuteProgressive 启动，每个服务返回后立即写入会话文件，查询已变化时停止翻译
+func ProgressCommand(tw *TranslateWorkflow, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: progress <session>")
+	}
+	sessionID := args[0]
+	session, err := tw.loadSession(sessionID)
+	if err != nil {
+		return err
+	}
+	if tw.currentSession() != sessionID {
+		return nil
+	}
+
+	services := tw.Services()
+	session.PID = os.Getpid()
+	session.Outcomes = make([]progressOutcome, len(services))
+	if err := tw.saveSession(sessionID, session); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tw.Timeout())
+	defer cancel()
+
+	var mu sync.Mutex
+	_, timeoutOccurred := tw.Collect(ctx, services, session.Query, func(outcome serviceOutcome) {
+		mu.Lock()
+		defer mu.Unlock()
+		if tw.currentSession() != sessionID {
+			cancel()
+			return
+		}
+		if outcome.index >= len(session.Outcomes) {
+			return
+		}
+		session.Outcomes[outcome.index] = progressOutcome{
+			Results: outcome.results,
+			Errors:  translate.Errors(outcome.err),
+			Done:    true,
+			Local:   outcome.local,
+		}
+		_ = tw.saveSession(sessionID, session)
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if tw.currentSession() != sessionID {
+		return nil
+	}
+	session.Finished = true
+	session.TimedOut = timeoutOccurred
+	return tw.saveSession(sessionID, session)
+}
+
+// startSession 创建会话文件并启动后台翻译进程，停止上一个查询未完成的后台进程
+// 翻译服务由后台进程创建，这里不创建服务，每次按键只需启动进程
+func (tw *TranslateWorkflow) startSession(query string) (string, *progressSession, error) {
+	tw.pruneSessions()
+	tw.stopSession(tw.currentSession())
+
+	session := &progressSession{
+		Query:   query,
+		Started: time.Now(),
+	}
+	sessionID := translate.Md5(fmt.Sprintf("%s\x00%d", query, session.Started.UnixNano()))
+	if err := tw.saveSession(sessionID, session); err != nil {
+		return "", nil, err
+	}
+	if err := tw.writeSessionFile(progressCurrentFile, []byte(sessionID)); err != nil {
+		return "", nil, err
+	}
+
+	execPath, err := os.Executable()
+	if err != nil {
+		return "", nil, err
+	}
+	args := []string{"progress", sessionID}
+	if tw.NoCache {
+		args = append([]string{"--no-cache"}, args...)
+	}
+	cmd := exec.Command(execPath, args...)
+	cmd.Dir = filepath.Dir(execPath)
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return "", nil, err
+	}
+	// 不等待后台进程退出，释放进程资源
+	_ = cmd.Process.Release()
+	return sessionID, session, nil
+}
+
+// sessionDir 会话文件目录
+func (tw *TranslateWorkflow) sessionDir() string {
+	return filepath.Join(alfred.CacheDir(workflowName), "progress")
+}
+
+// loadSession 读取会话文件
+func (tw *TranslateWorkflow) loadSession(sessionID string) (*progressSession, error) {
+	if sessionID == "" {
+		return nil, errors.New("empty session")
+	}
+	data, err := os.ReadFile(filepath.Join(tw.sessionDir(), sessionID+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var session progressSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// saveSession 写入会话文件
+func (tw *TranslateWorkflow) saveSession(sessionID string, session *progressSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return tw.writeSessionFile(sessionID+".json", data)
+}
+
+// writeSessionFile 写入会话目录中的文件，先写入唯一的临时文件再重命名，避免读取到不完整的内容
+func (tw *TranslateWorkflow) writeSessionFile(name string, data []byte) error {
+	dir := tw.sessionDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "session-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, name))
+}
+
+// currentSession 返回最新会话的ID
+func (tw *TranslateWorkflow) currentSession() string {
+	data, err := os.ReadFile(filepath.Join(tw.sessionDir(), progressCurrentFile))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// stopSession 结束未完成会话的后台进程并删除会话文件
+// 只处理未超时的会话，避免后台进程退出后进程号被其他进程复用
+func (tw *TranslateWorkflow) stopSession(sessionID string) {
+	session, err := tw.loadSession(sessionID)
+	if err != nil || session.Finished {
+		return
+	}
+	if session.PID > 0 && time.Since(session.Started) < tw.Timeout()+2*time.Second {
+		if process, err := os.FindProcess(session.PID); err == nil {
+			_ = process.Kill()
+		}
+	}
+	os.Remove(filepath.Join(tw.sessionDir(), sessionID+".json"))
+}
+
+// pruneSessions 删除过期的会话文件
+func (tw *TranslateWorkflow) pruneSessions() {
+	// 包括后台进程被结束时遗留的临时文件
+	files, err := filepath.Glob(filepath.Join(tw.sessionDir(), "*"))
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil && time.Since(info.ModTime()) > progressSessionMaxAge {
+			os.Remove(file)
+		}
+	}
+}
+
+// complete 所有服务都已返回或后台进程已结束
+func (s *progressSession) complete() bool {
+	if s.Finished {
+		return true
+	}
+	if s.Outcomes == nil {
+		return false
+	}
+	for _, outcome := range s.Outcomes {
+		if !outcome.Done {
+			return false
+		}
+	}
+	return true
+}
+
+// outcomes 转换为按服务顺序排列的结果
+func (s *progressSession) outcomes() []serviceOutcome {
+	outcomes := make([]serviceOutcome, len(s.Outcomes))
+	for i, outcome := range s.Outcomes {
+		outcomes[i] = serviceOutcome{
+			index:   i,
+			results: outcome.Results,
+			pending: !outcome.Done,
+			local:   outcome.Local,
+		}
+		if len(outcome.Errors) > 0 {
+			errs := make([]error, len(outcome.Errors))
+			for j, e := range outcome.Errors {
+				errs[j] = e
+			}
+			outcomes[i].err = errors.Join(errs...)
+		}
+	}
+	return outcomes
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"AlfredWorkflows/internal/core/translate"
+	"AlfredWorkflows/internal/platform/alfred"
+)
+
+// Usage 返回各服务的用量记录
+func (tw *TranslateWorkflow) Usage() *translate.Usage {
+	return translate.NewUsage(alfred.DataDir(workflowName))
+}
+
+// UsageCommand 显示各服务每月的请求数、字符数和剩余额度:
+//
+//	usage [年-月]   默认显示本月，如 usage 2024-05
+//	usage months   列出有记录的月份
+func UsageCommand(tw *TranslateWorkflow, args []string) error {
+	usage := tw.Usage()
+	month := translate.UsageMonth(time.Now())
+	switch {
+	case len(args) == 1 && args[0] == "months":
+		months, err := usage.Months()
+		if err != nil {
+			return err
+		}
+		for _, month := range months {
+			fmt.Println(month)
+		}
+		return nil
+	case len(args) == 1:
+		if _, err := time.Parse("2006-01", args[0]); err != nil {
+			return fmt.Errorf("usage: usage [yyyy-mm|months]")
+		}
+		month = args[0]
+	case len(args) > 1:
+		return fmt.Errorf("usage: usage [yyyy-mm|months]")
+	}
+
+	stats, err := usage.Month(month)
+	if err != nil {
+		return err
+	}
+
+	// 先按配置顺序列出服务，同名服务按账号分别列出，再列出已不在配置中但有记录的服务
+	quotas := map[string]translate.UsageQuota{}
+	var names []string
+	for _, item := range tw.Config.Services {
+		name := tw.Config.UsageKey(item)
+		if _, ok := quotas[name]; ok || item.Name == "dict" {
+			continue
+		}
+		quotas[name] = item.Quota
+		names = append(names, name)
+	}
+	var others []string
+	for name := range stats {
+		if _, ok := quotas[name]; !ok {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	names = append(names, others...)
+
+	fmt.Printf("%s 用量\n", month)
+	for _, name := range names {
+		s, quota := stats[name], quotas[name]
+		line := fmt.Sprintf("%s: %d次请求 %d字符", name, s.Requests, s.Chars)
+		switch {
+		case quota.Empty():
+			line += "，不限额度"
+		case s.Exceeds(quota):
+			line += "，额度 " + quotaText(quota) + " 已用完"
+		default:
+			line += "，额度 " + quotaText(quota) + " " + s.Remaining(quota)
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// quotaText 返回额度的说明，如 500000字符 1000次
+func quotaText(quota translate.UsageQuota) string {
+	text := ""
+	if quota.Chars > 0 {
+		text = strconv.Itoa(quota.Chars) + "字符"
+	}
+	if quota.Requests > 0 {
+		if text != "" {
+			text += " "
+		}
+		text += strconv.Itoa(quota.Requests) + "次"
+	}
+	return text
+}
@@ -0,0 +1,300 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// azureAPIURL Azure Translator v3 全局终结点
+const azureAPIURL = "https://api.cognitive.microsofttranslator.com"
+
+// azureDefaultTargets 默认目标语言，与检测到的源语言相同的目标会被忽略，实现中英互译
+var azureDefaultTargets = []string{"zh-Hans", "en"}
+
+// azureScripts 非拉丁文字语种对应的书写系统，用于音译为拉丁字母
+var azureScripts = map[string]string{
+	"zh-Hans": "Hans",
+	"zh-Hant": "Hant",
+	"ja":      "Jpan",
+	"ko":      "Kore",
+	"ru":      "Cyrl",
+	"uk":      "Cyrl",
+	"ar":      "Arab",
+	"fa":      "Arab",
+	"hi":      "Deva",
+	"th":      "Thai",
+	"el":      "Grek",
+	"he":      "Hebr",
+}
+
+// AzureTranslationResult Azure翻译结果
+type AzureTranslationResult struct {
+	DetectedLanguage *struct {
+		Language string  `json:"language"`
+		Score    float64 `json:"score"`
+	} `json:"detectedLanguage,omitempty"`
+	Translations []struct {
+		Text string `json:"text"`
+		To   string `json:"to"`
+	} `json:"translations"`
+}
+
+// AzureTransliterationResult Azure音译结果
+type AzureTransliterationResult struct {
+	Text   string `json:"text"`
+	Script string `json:"script"`
+}
+
+// AzureDictionaryResult Azure词典查询结果
+type AzureDictionaryResult struct {
+	NormalizedSource string `json:"normalizedSource"`
+	DisplaySource    string `json:"displaySource"`
+	Translations     []struct {
+		NormalizedTarget string  `json:"normalizedTarget"`
+		DisplayTarget    string  `json:"displayTarget"`
+		PosTag           string  `json:"posTag"`
+		Confidence       float64 `json:"confidence"`
+	} `json:"translations"`
+}
+
+// AzureError Azure接口错误
+type AzureError struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// azureUnsupportedLanguageCodes 表示语言不支持的错误码
+var azureUnsupportedLanguageCodes = map[int]bool{
+	400019: true, // 指定的语言不受支持
+	400023: true, // 目标语言无效
+	400035: true, // 源语言无效
+	400036: true, // 目标语言无效
+}
+
+// AzureErrorFromResponse 根据Azure返回的错误码创建错误，错误码前三位为HTTP状态码
+func AzureErrorFromResponse(code int, message string) *Error {
+	kind := ErrorUnknown
+	switch {
+	case azureUnsupportedLanguageCodes[code]:
+		kind = ErrorUnsupportedLanguage
+	case code/1000 == http.StatusUnauthorized:
+		kind = ErrorAuth
+	case code/1000 == http.StatusForbidden:
+		kind = ErrorQuota
+	case code/1000 == http.StatusTooManyRequests:
+		kind = ErrorRateLimited
+	case code/1000 >= http.StatusInternalServerError:
+		kind = ErrorBadResponse
+	}
+	return NewError(ProviderAzure, kind, strconv.Itoa(code), message)
+}
+
+// AzureService Azure Translator 翻译服务
+type AzureService struct {
+	Key     string
+	Region  string
+	URL     string
+	Targets []string
+	Client  *http.Client // 为空时使用默认HTTP客户端
+}
+
+// NewAzureService 创建Azure翻译服务
+func NewAzureService(key, region string, targets []string) *AzureService {
+	if len(targets) == 0 {
+		targets = azureDefaultTargets
+	}
+	return &AzureService{
+		Key:     key,
+		Region:  region,
+		URL:     azureAPIURL,
+		Targets: targets,
+	}
+}
+
+// Translate 使用Azure翻译服务翻译，一次请求翻译为所有目标语言
+func (s *AzureService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
+	var results []TranslationResult
+
+	params := url.Values{}
+	params.Add("api-version", "3.0")
+	for _, target := range s.Targets {
+		params.Add("to", target)
+	}
+
+	var translated []AzureTranslationResult
+	if err := s.call(ctx, "/translate", params, query, &translated); err != nil {
+		return nil, err
+	}
+	if len(translated) == 0 {
+		return nil, NewError(ProviderAzure, ErrorBadResponse, "", "翻译结果为空")
+	}
+
+	source := ""
+	confidence := ""
+	if detected := translated[0].DetectedLanguage; detected != nil {
+		source = detected.Language
+		confidence = fmt.Sprintf(" %.0f%%", detected.Score*100)
+	}
+
+	// 过滤掉与源语言相同的目标语言
+	type translation struct {
+		text string
+		to   string
+	}
+	var translations []translation
+	for _, t := range translated[0].Translations {
+		if source != "" && sameLanguage(t.To, source) {
+			continue
+		}
+		translations = append(translations, translation{text: t.Text, to: t.To})
+	}
+
+	// 非拉丁文字的译文并发查询音译
+	romanizations := make([]string, len(translations))
+	var wg sync.WaitGroup
+	for i, t := range translations {
+		script, ok := azureScripts[t.to]
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, text, language, script string) {
+			defer wg.Done()
+			if romanization, err := s.Transliterate(ctx, text, language, script); err == nil {
+				romanizations[i] = romanization
+			}
+		}(i, t.text, t.to, script)
+	}
+	wg.Wait()
+
+	for i, t := range translations {
+		subtitle := fmt.Sprintf("Azure翻译(%s→%s%s)", source, t.to, confidence)
+		if romanizations[i] != "" {
+			subtitle += " [" + romanizations[i] + "]"
+		}
+		subtitle += ": " + query
+		results = append(results, TranslationResult{
+			Title:    t.text,
+			Subtitle: subtitle,
+			Value:    t.text,
+			Provider: ProviderAzure,
+
+			SourceLang: NormalizeLang(source),
+		})
+	}
+
+	// 单词查询补充词典释义，词典仅支持英语与其他语言之间互查
+	if IsSingleWord(query) && source != "" {
+		for _, t := range translations {
+			if !sameLanguage(source, "en") && !sameLanguage(t.to, "en") {
+				continue
+			}
+			entries, err := s.LookupDictionary(ctx, query, source, t.to)
+			if err != nil {
+				continue
+			}
+			results = append(results, entries...)
+		}
+	}
+
+	return results, nil
+}
+
+// Transliterate 使用 transliterate 接口将文本音译为拉丁字母
+func (s *AzureService) Transliterate(ctx context.Context, text, language, fromScript string) (string, error) {
+	params := url.Values{}
+	params.Add("api-version", "3.0")
+	params.Add("language", language)
+	params.Add("fromScript", fromScript)
+	params.Add("toScript", "Latn")
+
+	var transliterated []AzureTransliterationResult
+	if err := s.call(ctx, "/transliterate", params, text, &transliterated); err != nil {
+		return "", err
+	}
+	if len(transliterated) == 0 {
+		return "", NewError(ProviderAzure, ErrorBadResponse, "", "音译结果为空")
+	}
+	return transliterated[0].Text, nil
+}
+
+// LookupDictionary 使用 dictionary/lookup 接口查询单词的词典释义
+func (s *AzureService) LookupDictionary(ctx context.Context, word, from, to string) ([]TranslationResult, error) {
+	var results []TranslationResult
+
+	params := url.Values{}
+	params.Add("api-version", "3.0")
+	params.Add("from", from)
+	params.Add("to", to)
+
+	var lookup []AzureDictionaryResult
+	if err := s.call(ctx, "/dictionary/lookup", params, word, &lookup); err != nil {
+		return nil, err
+	}
+	if len(lookup) == 0 {
+		return nil, nil
+	}
+
+	for _, t := range lookup[0].Translations {
+		results = append(results, TranslationResult{
+			Title:    t.DisplayTarget,
+			Subtitle: fmt.Sprintf("Azure词典(%s→%s %s %.0f%%): %s", from, to, strings.ToLower(t.PosTag), t.Confidence*100, lookup[0].DisplaySource),
+			Value:    t.DisplayTarget,
+			Provider: ProviderAzure,
+		})
+	}
+
+	return results, nil
+}
+
+// call 调用Azure Translator接口，将响应解析到 out
+func (s *AzureService) call(ctx context.Context, path string, params url.Values, text string, out interface{}) error {
+	jsonBody, err := json.Marshal([]map[string]string{{"Text": text}})
+	if err != nil {
+		return err
+	}
+
+	apiURL := strings.TrimRight(s.URL, "/") + path + "?" + params.Encode()
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Ocp-Apim-Subscription-Key", s.Key)
+	if s.Region != "" {
+		req.Header.Set("Ocp-Apim-Subscription-Region", s.Region)
+	}
+
+	status, body, err := doRequest(s.Client, req)
+	if err != nil {
+		return err
+	}
+
+	if status != http.StatusOK {
+		var azureErr AzureError
+		if err := json.Unmarshal(body, &azureErr); err == nil && azureErr.Error.Message != "" {
+			return AzureErrorFromResponse(azureErr.Error.Code, azureErr.Error.Message)
+		}
+	}
+
+	return decodeJSON(status, body, out)
+}
+
+// sameLanguage 判断两个语言代码是否为同一语种，如 en 与 en-GB；两者都带书写系统或地区时需完全一致
+func sameLanguage(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	if strings.Contains(a, "-") && strings.Contains(b, "-") {
+		return a == b
+	}
+	return strings.SplitN(a, "-", 2)[0] == strings.SplitN(b, "-", 2)[0]
+}
@@ -0,0 +1,148 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// baiduAPIURL 百度通用翻译API地址
+const baiduAPIURL = "https://fanyi-api.baidu.com/api/trans/vip/translate"
+
+// baiduErrorMessages 百度翻译错误码说明
+// 参考: https://fanyi-api.baidu.com/doc/21
+var baiduErrorMessages = map[string]string{
+	"52001": "请求超时，请重试",
+	"52002": "系统错误，请重试",
+	"52003": "未授权用户，请检查appid是否正确或服务是否开通",
+	"54000": "必填参数为空",
+	"54001": "签名错误，请检查appid和密钥",
+	"54003": "访问频率受限，请降低调用频率或升级认证",
+	"54004": "账户余额不足",
+	"54005": "长query请求频繁，请降低长query的发送频率",
+	"58000": "客户端IP非法，请检查IP白名单设置",
+	"58001": "译文语言方向不支持",
+	"58002": "服务当前已关闭，请前往管理控制台开启服务",
+	"90107": "认证未通过或未生效",
+}
+
+// BaiduTranslationResult 百度翻译结果
+type BaiduTranslationResult struct {
+	ErrorCode   json.Number `json:"error_code,omitempty"`
+	ErrorMsg    string      `json:"error_msg,omitempty"`
+	From        string      `json:"from"`
+	To          string      `json:"to"`
+	TransResult []struct {
+		Src string `json:"src"`
+		Dst string `json:"dst"`
+	} `json:"trans_result"`
+}
+
+// BaiduService 百度翻译服务
+type BaiduService struct {
+	AppID  string
+	Secret string
+	URL    string
+	Client *http.Client // 为空时使用默认HTTP客户端
+}
+
+// NewBaiduService 创建百度翻译服务
+func NewBaiduService(appID, secret string) *BaiduService {
+	return &BaiduService{
+		AppID:  appID,
+		Secret: secret,
+		URL:    baiduAPIURL,
+	}
+}
+
+// BaiduSign 计算百度翻译签名 md5(appid+q+salt+密钥)
+func BaiduSign(appID, query, salt, secret string) string {
+	return Md5(appID + query + salt + secret)
+}
+
+// baiduErrorKinds 百度翻译错误码对应的错误类型，未列出的为 ErrorUnknown
+var baiduErrorKinds = map[string]ErrorKind{
+	"52001": ErrorNetwork,
+	"52003": ErrorAuth,
+	"54001": ErrorAuth,
+	"54003": ErrorRateLimited,
+	"54004": ErrorQuota,
+	"54005": ErrorRateLimited,
+	"58000": ErrorAuth,
+	"58001": ErrorUnsupportedLanguage,
+	"58002": ErrorAuth,
+	"90107": ErrorAuth,
+}
+
+// BaiduErrorMessage 返回百度翻译错误码对应的说明
+func BaiduErrorMessage(code string) string {
+	if msg, ok := baiduErrorMessages[code]; ok {
+		return msg
+	}
+	return "未知错误"
+}
+
+// Translate 使用百度翻译服务翻译
+func (s *BaiduService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
+	var results []TranslationResult
+
+	targetLang := "en"
+	if !HasChineseChar(query) {
+		targetLang = "zh"
+	}
+
+	salt := strconv.FormatInt(time.Now().UnixNano(), 10)
+	params := url.Values{}
+	params.Add("q", query)
+	params.Add("from", "auto")
+	params.Add("to", targetLang)
+	params.Add("appid", s.AppID)
+	params.Add("salt", salt)
+	params.Add("sign", BaiduSign(s.AppID, query, salt, s.Secret))
+
+	// 使用POST表单提交，避免长文本超出URL长度限制
+	req, err := http.NewRequestWithContext(ctx, "POST", s.URL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	status, body, err := doRequest(s.Client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result BaiduTranslationResult
+	if err := decodeJSON(status, body, &result); err != nil {
+		return nil, err
+	}
+
+	if code := result.ErrorCode.String(); code != "" && code != "52000" {
+		return nil, NewError(ProviderBaidu, baiduErrorKinds[code], code, BaiduErrorMessage(code))
+	}
+
+	if len(result.TransResult) == 0 {
+		return nil, NewError(ProviderBaidu, ErrorBadResponse, "", "翻译结果为空")
+	}
+
+	// 多段落文本会按换行拆分为多条结果，按原顺序合并
+	paragraphs := make([]string, 0, len(result.TransResult))
+	for _, item := range result.TransResult {
+		paragraphs = append(paragraphs, item.Dst)
+	}
+
+	results = append(results, TranslationResult{
+		Title:    strings.Join(paragraphs, " "),
+		Subtitle: "百度翻译: " + query,
+		Value:    strings.Join(paragraphs, "\n"),
+		Provider: ProviderBaidu,
+
+		SourceLang: NormalizeLang(result.From),
+	})
+
+	return results, nil
+}
@@ -0,0 +1,215 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultBreakerFailures 默认连续失败多少次后熔断
+	defaultBreakerFailures = 3
+	// defaultBreakerCooldown 默认熔断持续时间，之后进入半开状态放行一次试探请求
+	defaultBreakerCooldown = 60 * time.Second
+)
+
+// 熔断器状态
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// ErrCircuitOpen 服务处于熔断状态
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// BreakerConfig 熔断配置
+type BreakerConfig struct {
+	Failures int `yaml:"failures,omitempty"` // 连续失败次数
+	Cooldown int `yaml:"cooldown,omitempty"` // 熔断时间 秒
+}
+
+// BreakerState 单个服务实例的熔断状态
+type BreakerState struct {
+	State    string    `json:"state"`
+	Failures int       `json:"failures"`
+	OpenedAt time.Time `json:"opened_at"`
+}
+
+// Breaker 熔断器，状态保存在文件中，在多次调用之间共享
+type Breaker struct {
+	Path     string
+	Failures int
+	Cooldown time.Duration
+
+	mu sync.Mutex
+}
+
+// NewBreaker 创建熔断器，状态保存在 path
+func NewBreaker(path string, config BreakerConfig) *Breaker {
+	failures := config.Failures
+	if failures <= 0 {
+		failures = defaultBreakerFailures
+	}
+	cooldown := time.Duration(config.Cooldown) * time.Second
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return &Breaker{
+		Path:     path,
+		Failures: failures,
+		Cooldown: cooldown,
+	}
+}
+
+// State 返回服务实例当前的熔断状态
+func (b *Breaker) State(instance string) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.load()[instance]
+}
+
+// Allow 判断是否放行请求，熔断时间结束后进入半开状态只放行一次试探
+func (b *Breaker) Allow(instance string) bool {
+	allow := true
+	b.update(func(states map[string]BreakerState) bool {
+		state := states[instance]
+		switch state.State {
+		case BreakerOpen:
+			if time.Since(state.OpenedAt) < b.Cooldown {
+				allow = false
+				return false
+			}
+			state.State = BreakerHalfOpen
+		case BreakerHalfOpen:
+			// 试探请求未返回结果(如进程被中断)时，超过熔断时间后允许再次试探
+			if time.Since(state.OpenedAt) < b.Cooldown {
+				allow = false
+				return false
+			}
+		default:
+			return false
+		}
+		state.OpenedAt = time.Now()
+		states[instance] = state
+		return true
+	})
+	return allow
+}
+
+// Success 记录成功，关闭熔断
+func (b *Breaker) Success(instance string) {
+	b.update(func(states map[string]BreakerState) bool {
+		if _, ok := states[instance]; !ok {
+			return false
+		}
+		delete(states, instance)
+		return true
+	})
+}
+
+// Failure 记录失败，连续失败达到阈值或半开试探失败时熔断
+func (b *Breaker) Failure(instance string) {
+	b.update(func(states map[string]BreakerState) bool {
+		state := states[instance]
+		state.Failures++
+		if state.State == BreakerHalfOpen || state.Failures >= b.Failures {
+			state.State = BreakerOpen
+			state.OpenedAt = time.Now()
+		} else if state.State == "" {
+			state.State = BreakerClosed
+		}
+		states[instance] = state
+		return true
+	})
+}
+
+// update 在文件锁内读取、修改并写入状态，多个进程同时修改时不会互相覆盖，fn 返回 false 时不写入
+// 无法加锁时仍然修改，状态只用于减少对故障服务的请求
+func (b *Breaker) update(fn func(states map[string]BreakerState) bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	_ = os.MkdirAll(filepath.Dir(b.Path), 0o755)
+	if unlock, err := lockFile(b.Path + ".lock"); err == nil {
+		defer unlock()
+	}
+	states := b.load()
+	if fn(states) {
+		b.save(states)
+	}
+}
+
+// load 读取状态文件，每次读取以获得其他进程的最新状态
+func (b *Breaker) load() map[string]BreakerState {
+	states := map[string]BreakerState{}
+	data, err := os.ReadFile(b.Path)
+	if err != nil {
+		return states
+	}
+	_ = json.Unmarshal(data, &states)
+	return states
+}
+
+// save 写入状态文件
+func (b *Breaker) save(states map[string]BreakerState) {
+	data, err := json.Marshal(states)
+	if err != nil {
+		return
+	}
+	dir := filepath.Dir(b.Path)
+
+	// 先写入同目录下唯一的临时文件再重命名，避免其他进程读取到不完整的内容或同时写入同一个临时文件
+	tmp, err := os.CreateTemp(dir, "breaker-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	_ = os.Rename(tmp.Name(), b.Path)
+}
+
+// BreakerService 带熔断的翻译服务
+type BreakerService struct {
+	Service  Service
+	Breaker  *Breaker
+	Instance string
+}
+
+// NewBreakerService 为翻译服务添加熔断
+func NewBreakerService(service Service, breaker *Breaker, instance string) *BreakerService {
+	return &BreakerService{
+		Service:  service,
+		Breaker:  breaker,
+		Instance: instance,
+	}
+}
+
+// Translate 熔断时直接返回带服务提供方名称的 *Error(原始错误为 ErrCircuitOpen)，只有网络错误、超时和服务端错误计入失败
+func (s *BreakerService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
+	if !s.Breaker.Allow(s.Instance) {
+		err := NewError(providerName(s.Service), ErrorNetwork, "", "连续失败，暂停调用")
+		err.Err = ErrCircuitOpen
+		return nil, err
+	}
+
+	results, err := s.Service.Translate(ctx, query)
+	switch {
+	case err == nil:
+		s.Breaker.Success(s.Instance)
+	case IsRetryable(err):
+		s.Breaker.Failure(s.Instance)
+	}
+	return results, err
+}
@@ -0,0 +1,66 @@
+package translate
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestBreakerServiceOpenError(t *testing.T) {
+	calls := 0
+	inner := &providerService{provider: ProviderDeeplx, service: serviceFunc(func(ctx context.Context, query string) ([]TranslationResult, error) {
+		calls++
+		return nil, &HTTPStatusError{StatusCode: 503}
+	})}
+	breaker := NewBreaker(filepath.Join(t.TempDir(), "breaker.json"), BreakerConfig{Failures: 2})
+	service := NewBreakerService(NewRetryService(NewMeteredService(inner, NewUsage(t.TempDir()), "deeplx", UsageQuota{}), 0, 0), breaker, "deeplx")
+
+	for i := 0; i < 2; i++ {
+		if _, err := service.Translate(context.Background(), "hello"); err == nil {
+			t.Fatalf("Translate() #%d error = nil, want 503", i+1)
+		}
+	}
+	if state := breaker.State("deeplx"); state.State != BreakerOpen {
+		t.Fatalf("State() = %q, want %q", state.State, BreakerOpen)
+	}
+
+	_, err := service.Translate(context.Background(), "hello")
+	if calls != 2 {
+		t.Errorf("inner calls = %d, want 2 (open breaker should not call the service)", calls)
+	}
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Translate() error = %v, want ErrCircuitOpen", err)
+	}
+	// 熔断错误带有服务名称和类型，错误列表中显示为该服务的一项
+	errs := Errors(err)
+	if len(errs) != 1 {
+		t.Fatalf("Errors() = %v, want one error", errs)
+	}
+	if errs[0].Provider != ProviderDeeplx || errs[0].Kind != ErrorNetwork || errs[0].Message != "连续失败，暂停调用" {
+		t.Errorf("Errors()[0] = %+v, want provider %s, kind %v", errs[0], ProviderDeeplx, ErrorNetwork)
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	breaker := NewBreaker(filepath.Join(t.TempDir(), "breaker.json"), BreakerConfig{Failures: 1, Cooldown: 1})
+	breaker.Failure("a")
+	if breaker.Allow("a") {
+		t.Fatal("Allow() after tripping = true, want false")
+	}
+
+	// 熔断时间结束后放行一次试探
+	state := breaker.State("a")
+	state.OpenedAt = state.OpenedAt.Add(-breaker.Cooldown)
+	breaker.save(map[string]BreakerState{"a": state})
+	if !breaker.Allow("a") {
+		t.Fatal("Allow() after cooldown = false, want true")
+	}
+	if breaker.Allow("a") {
+		t.Error("second Allow() while half-open = true, want false")
+	}
+	breaker.Success("a")
+	if state := breaker.State("a"); state.State != "" {
+		t.Errorf("State() after success = %q, want closed", state.State)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build unix
+
+package translate
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestBreakerFailureAcrossProcesses(t *testing.T) {
+	// 各自的 Breaker 模拟不同进程，只靠文件锁互斥，失败次数不会因同时写入而丢失
+	path := filepath.Join(t.TempDir(), "breaker.json")
+	const breakers, failures = 4, 25
+	var wg sync.WaitGroup
+	for i := 0; i < breakers; i++ {
+		breaker := NewBreaker(path, BreakerConfig{Failures: breakers * failures * 2})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < failures; j++ {
+				breaker.Failure("a")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := NewBreaker(path, BreakerConfig{}).State("a").Failures; got != breakers*failures {
+		t.Errorf("Failures = %d, want %d", got, breakers*failures)
+	}
+}
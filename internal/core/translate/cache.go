@@ -0,0 +1,220 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultCacheTTL 默认缓存有效期
+	defaultCacheTTL = 7 * 24 * time.Hour
+	// defaultCacheMaxEntries 默认最多缓存的翻译条数
+	defaultCacheMaxEntries = 1000
+)
+
+// CacheConfig 翻译缓存配置
+type CacheConfig struct {
+	Disabled   bool `yaml:"disabled,omitempty"`
+	TTL        int  `yaml:"ttl,omitempty"`         // 有效期 秒
+	MaxEntries int  `yaml:"max_entries,omitempty"` // 最多缓存条数，超出时淘汰最旧的
+}
+
+// cacheEntry 缓存文件内容
+type cacheEntry struct {
+	Key     string              `json:"key"`
+	Created time.Time           `json:"created"`
+	Results []TranslationResult `json:"results"`
+}
+
+// Cache 基于文件的翻译结果缓存，每条结果一个文件
+type Cache struct {
+	Dir        string
+	TTL        time.Duration
+	MaxEntries int
+}
+
+// NewCache 创建翻译缓存，缓存文件保存在 dir 下
+func NewCache(dir string, config CacheConfig) *Cache {
+	ttl := time.Duration(config.TTL) * time.Second
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	maxEntries := config.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultCacheMaxEntries
+	}
+	return &Cache{
+		Dir:        dir,
+		TTL:        ttl,
+		MaxEntries: maxEntries,
+	}
+}
+
+// NormalizeQuery 规范化查询文本，去除首尾空白并合并连续空白
+func NormalizeQuery(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// QueryDirection 返回查询的源语言和目标语言，与各服务的中英互译规则一致
+func QueryDirection(query string) (string, string) {
+	if HasChineseChar(query) {
+		return "auto", "en"
+	}
+	return "auto", "zh"
+}
+
+// Key 计算缓存键，由服务实例、规范化的查询、源语言和目标语言组成
+func (c *Cache) Key(instance, query string) string {
+	normalized := NormalizeQuery(query)
+	source, target := QueryDirection(normalized)
+	return strings.Join([]string{instance, source, target, normalized}, "\x00")
+}
+
+// Get 读取未过期的缓存结果
+func (c *Cache) Get(key string) ([]TranslationResult, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+
+	// 哈希碰撞或过期都视为未命中
+	if entry.Key != key || time.Since(entry.Created) > c.TTL {
+		return nil, false
+	}
+	return entry.Results, true
+}
+
+// Set 写入缓存结果，并淘汰过期和超出数量限制的缓存
+func (c *Cache) Set(key string, results []TranslationResult) error {
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(cacheEntry{
+		Key:     key,
+		Created: time.Now(),
+		Results: results,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 先写入临时文件再重命名，避免并发读取到不完整的内容
+	tmp, err := os.CreateTemp(c.Dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return err
+	}
+
+	c.prune()
+	return nil
+}
+
+// Clear 清空所有缓存
+func (c *Cache) Clear() error {
+	files, err := filepath.Glob(filepath.Join(c.Dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// path 缓存键对应的文件路径
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, Sha256(key)+".json")
+}
+
+// prune 删除过期缓存，并按修改时间淘汰超出数量限制的最旧缓存
+func (c *Cache) prune() {
+	files, err := filepath.Glob(filepath.Join(c.Dir, "*.json"))
+	if err != nil {
+		return
+	}
+
+	type cacheFile struct {
+		path    string
+		modTime time.Time
+	}
+	var valid []cacheFile
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if time.Since(info.ModTime()) > c.TTL {
+			os.Remove(file)
+			continue
+		}
+		valid = append(valid, cacheFile{path: file, modTime: info.ModTime()})
+	}
+
+	if len(valid) <= c.MaxEntries {
+		return
+	}
+	sort.Slice(valid, func(i, j int) bool {
+		return valid[i].modTime.Before(valid[j].modTime)
+	})
+	for _, file := range valid[:len(valid)-c.MaxEntries] {
+		os.Remove(file.path)
+	}
+}
+
+// CachedService 带缓存的翻译服务
+type CachedService struct {
+	Service  Service
+	Cache    *Cache
+	Instance string // 服务实例标识，区分同类服务的不同配置
+}
+
+// NewCachedService 为翻译服务添加缓存
+func NewCachedService(service Service, cache *Cache, instance string) *CachedService {
+	return &CachedService{
+		Service:  service,
+		Cache:    cache,
+		Instance: instance,
+	}
+}
+
+// Translate 优先返回缓存结果，未命中时调用服务翻译并缓存成功的结果
+func (s *CachedService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
+	key := s.Cache.Key(s.Instance, query)
+	if results, ok := s.Cache.Get(key); ok {
+		return results, nil
+	}
+
+	results, err := s.Service.Translate(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) > 0 {
+		_ = s.Cache.Set(key, results)
+	}
+	return results, nil
+}
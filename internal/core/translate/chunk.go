@@ -0,0 +1,420 @@
+package translate
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// defaultMaxChars 未知服务单次请求的最大字符数
+	defaultMaxChars = 3000
+	// defaultChunkConcurrency 同一服务同时翻译的分段数
+	defaultChunkConcurrency = 4
+)
+
+// providerMaxChars 各服务单次请求的最大字符数，按官方限制适当留有余量
+var providerMaxChars = map[string]int{
+	"youdao":  5000,
+	"deeplx":  5000,
+	"deepl":   30000,
+	"baidu":   2000, // 限制为6000字节
+	"tencent": 5000,
+	"azure":   10000,
+}
+
+// MaxChars 返回服务单次请求的最大字符数，配置了 max_chars 时优先使用
+func (c ConfigItem) MaxChars() int {
+	if c.MaxCharsLimit > 0 {
+		return c.MaxCharsLimit
+	}
+	if limit, ok := providerMaxChars[c.Name]; ok {
+		return limit
+	}
+	return defaultMaxChars
+}
+
+var (
+	// linePrefixPattern 行首需要原样保留的缩进、列表标记、引用和标题标记
+	linePrefixPattern = regexp.MustCompile(`^\s*(?:(?:[-*+]|\d+[.)])\s+(?:\[[ xX]\]\s+)?|>\s*|#{1,6}\s+)*`)
+	// codeFencePattern 代码块的开始或结束行
+	codeFencePattern = regexp.MustCompile("^\\s*(```|~~~)")
+	// codeSpanPattern 行内代码
+	codeSpanPattern = regexp.MustCompile("`[^`\n]+`")
+	// codeSpanPlaceholderPattern 行内代码占位符，只匹配生成的 ⟪n⟫，不影响原文中的《1》、« 2 » 等
+	codeSpanPlaceholderPattern = regexp.MustCompile(`⟪\s*(\d+)\s*⟫`)
+	// sentenceEndPattern 句子结尾：中日文句末标点，或英文句末标点后跟空白
+	sentenceEndPattern = regexp.MustCompile(`[。！？；…]+[”’」』）)]*|[.!?;]+["')\]]*\s+`)
+)
+
+// textLine 文本中的一行，只翻译 content 部分
+type textLine struct {
+	prefix    string // 缩进、列表标记等
+	content   string
+	suffix    string // 行尾空白
+	translate bool   // 空行和代码块中的行不翻译
+}
+
+// textUnit 一次翻译的最小单位：一行，或超长行中的一句
+type textUnit struct {
+	line  int
+	text  string
+	codes map[int]string // 占位符序号 → 被替换的行内代码
+}
+
+// ChunkedService 将超长或带格式的文本分段翻译并按原有格式重组的翻译服务
+// 不超过长度限制且没有需要保留的格式时直接交给服务翻译，保留服务的全部结果(多个目标语言、备选译文、词典释义等)
+type ChunkedService struct {
+	Service     Service
+	MaxChars    int
+	Concurrency int
+}
+
+// NewChunkedService 为翻译服务添加分段翻译
+func NewChunkedService(service Service, maxChars int) *ChunkedService {
+	if maxChars <= 0 {
+		maxChars = defaultMaxChars
+	}
+	return &ChunkedService{
+		Service:     service,
+		MaxChars:    maxChars,
+		Concurrency: defaultChunkConcurrency,
+	}
+}
+
+// SupportsGlossary 服务是否支持原生术语表
+func (s *ChunkedService) SupportsGlossary() bool {
+	supporter, ok := s.Service.(GlossarySupporter)
+	return ok && supporter.SupportsGlossary()
+}
+
+// Translate 分段翻译，保留换行、缩进、列表标记、代码块和行内代码
+func (s *ChunkedService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
+	if utf8.RuneCountInString(query) <= s.MaxChars && (!strings.Contains(query, "\n") || !hasLayout(splitLines(query))) {
+		return s.Service.Translate(ctx, query)
+	}
+
+	lines := splitLines(query)
+	units := s.units(lines)
+	if len(units) == 0 {
+		return nil, nil
+	}
+
+	translations, first, err := s.translateUnits(ctx, units)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按行重组，同一行的多句译文按语言决定是否以空格连接
+	contents := make([]string, len(lines))
+	for i, unit := range units {
+		text := restoreCodeSpans(translations[i], unit.codes)
+		if contents[unit.line] != "" && !endsWithCJK(contents[unit.line]) {
+			contents[unit.line] += " "
+		}
+		contents[unit.line] += text
+	}
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(line.prefix)
+		if line.translate {
+			b.WriteString(contents[i])
+		} else {
+			b.WriteString(line.content)
+		}
+		b.WriteString(line.suffix)
+	}
+	value := b.String()
+
+	return []TranslationResult{{
+		Title:    strings.Join(strings.Fields(value), " "),
+		Subtitle: querySubtitle(first.Subtitle, query),
+		Value:    value,
+		Provider: first.Provider,
+
+		SourceLang: first.SourceLang,
+	}}, nil
+}
+
+// querySubtitle 将分段结果副标题中的查询替换为完整查询，副标题格式为 "服务名: 查询"
+func querySubtitle(subtitle, query string) string {
+	if i := strings.LastIndex(subtitle, ": "); i >= 0 {
+		subtitle = subtitle[:i+2] + strings.Join(strings.Fields(query), " ")
+	}
+	return subtitle
+}
+
+// splitLines 拆分文本为行，识别代码块、行首标记和行尾空白
+func splitLines(text string) []textLine {
+	var lines []textLine
+	inFence := false
+	for _, raw := range strings.Split(text, "\n") {
+		trimmed := strings.TrimRightFunc(raw, unicode.IsSpace)
+		line := textLine{suffix: raw[len(trimmed):]}
+
+		switch {
+		case codeFencePattern.MatchString(trimmed):
+			inFence = !inFence
+			line.content = trimmed
+		case inFence || strings.TrimSpace(trimmed) == "":
+			line.content = trimmed
+		default:
+			line.prefix = linePrefixPattern.FindString(trimmed)
+			line.content = trimmed[len(line.prefix):]
+			line.translate = line.content != ""
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// hasLayout 是否有需要逐行保留的格式：代码块、缩进、列表标记、引用、标题或行内代码
+func hasLayout(lines []textLine) bool {
+	for _, line := range lines {
+		if line.prefix != "" || codeSpanPattern.MatchString(line.content) {
+			return true
+		}
+		// 代码块的标记行和其中的行
+		if !line.translate && strings.TrimSpace(line.content) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// units 将需要翻译的行转换为翻译单位，超长的行按句子拆分
+func (s *ChunkedService) units(lines []textLine) []textUnit {
+	var units []textUnit
+	for i, line := range lines {
+		if !line.translate {
+			continue
+		}
+		masked, codes := maskCodeSpans(line.content)
+		for _, sentence := range SplitSentences(masked, s.MaxChars) {
+			units = append(units, textUnit{line: i, text: sentence, codes: codes})
+		}
+	}
+	return units
+}
+
+// translateUnits 将翻译单位按长度限制打包后并发翻译，返回每个单位的译文和第一个结果
+// 打包的多行译文行数不一致时，改为逐行翻译
+func (s *ChunkedService) translateUnits(ctx context.Context, units []textUnit) ([]string, TranslationResult, error) {
+	var chunks [][]int
+	var current []int
+	size := 0
+	for i, unit := range units {
+		n := utf8.RuneCountInString(unit.text) + 1
+		if len(current) > 0 && size+n > s.MaxChars {
+			chunks = append(chunks, current)
+			current, size = nil, 0
+		}
+		current = append(current, i)
+		size += n
+	}
+	chunks = append(chunks, current)
+
+	translations := make([]string, len(units))
+	results := make([]TranslationResult, len(chunks))
+	errs := make([]error, len(chunks))
+
+	concurrency := s.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultChunkConcurrency
+	}
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for c, chunk := range chunks {
+		wg.Add(1)
+		go func(c int, chunk []int) {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				errs[c] = ctx.Err()
+				return
+			}
+			results[c], errs[c] = s.translateChunk(ctx, units, chunk, translations)
+		}(c, chunk)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, TranslationResult{}, err
+		}
+	}
+	return translations, results[0], nil
+}
+
+// translateChunk 翻译一个分段，译文写入 translations 中对应的位置
+func (s *ChunkedService) translateChunk(ctx context.Context, units []textUnit, chunk []int, translations []string) (TranslationResult, error) {
+	texts := make([]string, len(chunk))
+	for i, index := range chunk {
+		texts[i] = units[index].text
+	}
+
+	lines, result, err := TranslateLines(ctx, s.Service, texts)
+	if err != nil {
+		return TranslationResult{}, err
+	}
+	for i, index := range chunk {
+		translations[index] = lines[i]
+	}
+	return result, nil
+}
+
+// TranslateLines 将多条单行文本按行合并为一次查询，返回每条的译文和服务的第一个结果
+// 译文行数与原文不一致时改为逐条翻译
+func TranslateLines(ctx context.Context, service Service, texts []string) ([]string, TranslationResult, error) {
+	first, err := firstResult(ctx, service, strings.Join(texts, "\n"))
+	if err != nil {
+		return nil, TranslationResult{}, err
+	}
+
+	translations := make([]string, len(texts))
+	if len(texts) == 1 {
+		translations[0] = strings.TrimSpace(first.Value)
+		return translations, first, nil
+	}
+	if parts := nonEmptyLines(first.Value); len(parts) == len(texts) {
+		copy(translations, parts)
+		return translations, first, nil
+	}
+
+	for i, text := range texts {
+		result, err := firstResult(ctx, service, text)
+		if err != nil {
+			return nil, TranslationResult{}, err
+		}
+		translations[i] = strings.TrimSpace(result.Value)
+	}
+	return translations, first, nil
+}
+
+// firstResult 翻译文本，返回服务的第一个结果
+func firstResult(ctx context.Context, service Service, text string) (TranslationResult, error) {
+	results, err := service.Translate(ctx, text)
+	if err != nil {
+		return TranslationResult{}, err
+	}
+	if len(results) == 0 {
+		return TranslationResult{}, &Error{Kind: ErrorBadResponse, Message: "翻译结果为空"}
+	}
+	return results[0], nil
+}
+
+// SplitSentences 按中英文句末标点拆分文本，并把相邻的句子合并为不超过 maxChars 的片段
+// 单句超长时在空白处或按字符数强制拆分
+func SplitSentences(text string, maxChars int) []string {
+	if utf8.RuneCountInString(text) <= maxChars {
+		return []string{text}
+	}
+
+	var sentences []string
+	start := 0
+	for _, loc := range sentenceEndPattern.FindAllStringIndex(text, -1) {
+		sentences = append(sentences, text[start:loc[1]])
+		start = loc[1]
+	}
+	if start < len(text) {
+		sentences = append(sentences, text[start:])
+	}
+
+	var pieces []string
+	current := ""
+	for _, sentence := range sentences {
+		for _, part := range splitLong(sentence, maxChars) {
+			if current != "" && utf8.RuneCountInString(current)+utf8.RuneCountInString(part) > maxChars {
+				pieces = append(pieces, strings.TrimSpace(current))
+				current = ""
+			}
+			current += part
+		}
+	}
+	if strings.TrimSpace(current) != "" {
+		pieces = append(pieces, strings.TrimSpace(current))
+	}
+	return pieces
+}
+
+// splitLong 将超过 maxChars 的句子在最后一个空白处拆分，没有空白时按字符数拆分
+func splitLong(sentence string, maxChars int) []string {
+	var parts []string
+	for utf8.RuneCountInString(sentence) > maxChars {
+		runes := []rune(sentence)
+		cut := maxChars
+		for i := maxChars; i > maxChars/2; i-- {
+			if unicode.IsSpace(runes[i]) {
+				// 空白留在前一段末尾，正好在 maxChars 处时留给下一段
+				cut = minInt(i+1, maxChars)
+				break
+			}
+		}
+		parts = append(parts, string(runes[:cut]))
+		sentence = string(runes[cut:])
+	}
+	return append(parts, sentence)
+}
+
+// maskCodeSpans 将行内代码替换为占位符，跳过原文中已出现的占位符序号
+func maskCodeSpans(text string) (string, map[int]string) {
+	reserved := map[int]bool{}
+	for _, match := range codeSpanPlaceholderPattern.FindAllStringSubmatch(text, -1) {
+		if i, err := strconv.Atoi(match[1]); err == nil {
+			reserved[i] = true
+		}
+	}
+
+	codes := map[int]string{}
+	next := 0
+	masked := codeSpanPattern.ReplaceAllStringFunc(text, func(code string) string {
+		for reserved[next] {
+			next++
+		}
+		codes[next] = code
+		next++
+		return "⟪" + strconv.Itoa(next-1) + "⟫"
+	})
+	return masked, codes
+}
+
+// restoreCodeSpans 将占位符还原为行内代码，只还原生成的序号
+func restoreCodeSpans(text string, codes map[int]string) string {
+	if len(codes) == 0 {
+		return text
+	}
+	return codeSpanPlaceholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		i, err := strconv.Atoi(codeSpanPlaceholderPattern.FindStringSubmatch(match)[1])
+		if code, ok := codes[i]; err == nil && ok {
+			return code
+		}
+		return match
+	})
+}
+
+// nonEmptyLines 返回非空的行
+func nonEmptyLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// endsWithCJK 文本是否以中日韩文字或全角标点结尾
+func endsWithCJK(text string) bool {
+	r, _ := utf8.DecodeLastRuneInString(text)
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r) || (r >= 0x3000 && r <= 0x303F) || (r >= 0xFF00 && r <= 0xFFEF)
+}
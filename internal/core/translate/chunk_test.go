@@ -0,0 +1,149 @@
+package translate
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// serviceFunc 用函数实现的翻译服务，用于测试
+type serviceFunc func(ctx context.Context, query string) ([]TranslationResult, error)
+
+// Translate 实现 Service 接口
+func (f serviceFunc) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
+	return f(ctx, query)
+}
+
+func TestSplitLong(t *testing.T) {
+	tests := []struct {
+		name     string
+		sentence string
+		maxChars int
+		want     []string
+	}{
+		{name: "未超长不拆分", sentence: "hello world", maxChars: 20, want: []string{"hello world"}},
+		{name: "在最后一个空白处拆分", sentence: "aaa bbb ccc ddd", maxChars: 10, want: []string{"aaa bbb ", "ccc ddd"}},
+		{name: "空白正好在限制处", sentence: "aaaa bbbb", maxChars: 4, want: []string{"aaaa", " bbb", "b"}},
+		{name: "空白在前半段时按字符数拆分", sentence: "a bcdefghijk", maxChars: 6, want: []string{"a bcde", "fghijk"}},
+		{name: "中文按字符而不是字节拆分", sentence: "一二三四五六七八九十", maxChars: 4, want: []string{"一二三四", "五六七八", "九十"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitLong(tt.sentence, tt.maxChars)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("splitLong(%q, %d) = %q, want %q", tt.sentence, tt.maxChars, got, tt.want)
+			}
+			if strings.Join(got, "") != tt.sentence {
+				t.Errorf("splitLong(%q) lost text: %q", tt.sentence, got)
+			}
+			for _, part := range got {
+				if utf8.RuneCountInString(part) > tt.maxChars {
+					t.Errorf("part %q longer than %d", part, tt.maxChars)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		text     string
+		maxChars int
+		want     []string
+	}{
+		{text: "Short one.", maxChars: 50, want: []string{"Short one."}},
+		{text: "First one. Second one. Third one.", maxChars: 23, want: []string{"First one. Second one.", "Third one."}},
+		{text: "第一句。第二句！第三句？", maxChars: 8, want: []string{"第一句。第二句！", "第三句？"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := SplitSentences(tt.text, tt.maxChars); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("SplitSentences(%q, %d) = %q, want %q", tt.text, tt.maxChars, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkedServicePassThrough(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		maxChars  int
+		wantCalls int
+		wantPass  bool // 是否原样返回服务的全部结果
+	}{
+		{name: "单行", query: "hello", maxChars: 100, wantCalls: 1, wantPass: true},
+		{name: "多行普通文本", query: "hello\nworld", maxChars: 100, wantCalls: 1, wantPass: true},
+		{name: "列表需要保留格式", query: "- hello\n- world", maxChars: 100, wantCalls: 1},
+		{name: "代码块需要保留格式", query: "run\n```\nls\n```", maxChars: 100, wantCalls: 1},
+		{name: "超长文本分段", query: "First one. Second one.", maxChars: 12, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			service := serviceFunc(func(ctx context.Context, query string) ([]TranslationResult, error) {
+				calls++
+				// 模拟返回多个目标语言的服务
+				return []TranslationResult{
+					{Title: "T:" + query, Value: "T:" + query, Subtitle: "测试: " + query},
+					{Title: "alt", Value: "alt", Subtitle: "测试: " + query},
+				}, nil
+			})
+			results, err := NewChunkedService(service, tt.maxChars).Translate(context.Background(), tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if pass := len(results) == 2; pass != tt.wantPass {
+				t.Errorf("results = %+v, pass through = %v, want %v", results, pass, tt.wantPass)
+			}
+		})
+	}
+}
+
+func TestChunkedServiceKeepsLayout(t *testing.T) {
+	service := serviceFunc(func(ctx context.Context, query string) ([]TranslationResult, error) {
+		return []TranslationResult{{Title: strings.ToUpper(query), Value: strings.ToUpper(query), Subtitle: "测试: " + query}}, nil
+	})
+	query := "# title\n\n- item `code`\n```\nkeep me\n```"
+	results, err := NewChunkedService(service, 100).Translate(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# TITLE\n\n- ITEM `code`\n```\nkeep me\n```"
+	if len(results) != 1 || results[0].Value != want {
+		t.Errorf("results = %+v, want value %q", results, want)
+	}
+}
+
+func TestCodeSpanPlaceholders(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		masked      string
+		translation string
+		want        string
+	}{
+		{name: "行内代码", text: "run `make` now", masked: "run ⟪0⟫ now", translation: "现在运行 ⟪ 0 ⟫", want: "现在运行 `make`"},
+		// 书名号和法文引号中的数字是原文，不是占位符
+		{name: "书名号中的数字", text: "见《1》和 `cfg`", masked: "见《1》和 ⟪0⟫", translation: "see 《1》 and ⟪0⟫", want: "see 《1》 and `cfg`"},
+		{name: "法文引号", text: "« 2 » `x`", masked: "« 2 » ⟪0⟫", translation: "« 2 » ⟪0⟫", want: "« 2 » `x`"},
+		// 原文中已有的占位符形式的文字原样保留，生成的占位符跳过该序号
+		{name: "原文含有占位符", text: "⟪0⟫ `a`", masked: "⟪0⟫ ⟪1⟫", translation: "⟪0⟫ ⟪1⟫", want: "⟪0⟫ `a`"},
+		{name: "没有行内代码", text: "《1》", masked: "《1》", translation: "《1》", want: "《1》"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			masked, codes := maskCodeSpans(tt.text)
+			if masked != tt.masked {
+				t.Errorf("maskCodeSpans(%q) = %q, want %q", tt.text, masked, tt.masked)
+			}
+			if got := restoreCodeSpans(tt.translation, codes); got != tt.want {
+				t.Errorf("restoreCodeSpans(%q) = %q, want %q", tt.translation, got, tt.want)
+			}
+		})
+	}
+}
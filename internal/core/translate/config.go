@@ -1,18 +1,98 @@
 package translate
 
+import (
+	"encoding/json"
+	"strings"
+)
+
 // ConfigItem 定义单个服务配置项
 type ConfigItem struct {
-	Name      string `yaml:"name"`
-	URL       string `yaml:"url,omitempty"`
-	Token     string `yaml:"token,omitempty"`
-	AppKey    string `yaml:"app_key,omitempty"`
-	AppSecret string `yaml:"app_secret,omitempty"`
+	Name      string   `yaml:"name"`
+	URL       string   `yaml:"url,omitempty"`
+	Token     string   `yaml:"token,omitempty"`
+	AppKey    string   `yaml:"app_key,omitempty"`
+	AppSecret string   `yaml:"app_secret,omitempty"`
+	Region    string   `yaml:"region,omitempty"`
+	Targets   []string `yaml:"targets,omitempty"`
+	SignType  string   `yaml:"sign_type,omitempty"` // 有道签名方式 v3(默认)/v1
+	Timeout   int      `yaml:"timeout,omitempty"`   // 单次请求超时 秒，0 表示使用全局超时
+	Retries   int      `yaml:"retries,omitempty"`   // 网络错误或服务端错误(5xx)时的重试次数
+	Path      string   `yaml:"path,omitempty"`      // 本地词典文件，相对路径相对于配置文件所在目录
+
+	// MaxCharsLimit 单次请求的最大字符数，超过时分段翻译，0 表示使用服务的默认限制
+	MaxCharsLimit int `yaml:"max_chars,omitempty"`
+	// RateLimit 每秒请求数，0 表示使用服务的默认限制(百度1、腾讯5，其余不限)，Burst 为允许的突发请求数
+	// 不影响服务实例标识
+	RateLimit float64 `yaml:"rate_limit,omitempty" json:"-"`
+	Burst     int     `yaml:"burst,omitempty" json:"-"`
+	// Quota 每月额度，用完后不再请求，回退链中改用下一个服务，不影响服务实例标识
+	Quota UsageQuota `yaml:"quota,omitempty" json:"-"`
+	// UsageName 用量统计的名称，设置相同名称的服务共用用量和额度，不影响服务实例标识
+	UsageName string `yaml:"usage_name,omitempty" json:"-"`
+
+	// DeepL 官方API选项
+	Formality   string `yaml:"formality,omitempty"`
+	GlossaryID  string `yaml:"glossary_id,omitempty"`
+	TagHandling string `yaml:"tag_handling,omitempty"`
+
+	// HTTP 代理、证书等设置，未设置的字段使用全局 http 配置
+	// 不影响服务实例标识，修改代理不会使缓存失效
+	HTTP HTTPConfig `yaml:",inline" json:"-"`
+
+	// StateDir 保存服务状态(如自动创建的DeepL术语表ID)的目录，由程序设置，不在配置文件中填写
+	StateDir string `yaml:"-" json:"-"`
 }
 
 // Config 定义整体配置结构体
 type Config struct {
-	Services []ConfigItem `yaml:"services"`
-	Timeout  int          `yaml:"timeout"`
+	Services []ConfigItem   `yaml:"services"`
+	Timeout  int            `yaml:"timeout"`
+	Speech   SpeechConfig   `yaml:"speech,omitempty"`
+	Cache    CacheConfig    `yaml:"cache,omitempty"`
+	HTTP     HTTPConfig     `yaml:"http,omitempty"`
+	Glossary GlossaryConfig `yaml:"glossary,omitempty"`
+	History  HistoryConfig  `yaml:"history,omitempty"`
+	Naming   NamingConfig   `yaml:"naming,omitempty"`
+	Markup   string         `yaml:"markup,omitempty"` // 标记识别 auto(默认)/html/xml/markdown/off
+	Privacy  PrivacyConfig  `yaml:"privacy,omitempty"`
+
+	// Fallback 按顺序回退的服务名称，如 [deeplx, youdao]，前一个失败时才调用下一个
+	// 回退链中的服务作为一个整体与其他服务并发查询
+	Fallback       []string       `yaml:"fallback,omitempty"`
+	CircuitBreaker BreakerConfig  `yaml:"circuit_breaker,omitempty"`
+	Progress       ProgressConfig `yaml:"progress,omitempty"`
+}
+
+// ProgressConfig Alfred 中逐步显示翻译结果的配置
+type ProgressConfig struct {
+	Disabled bool    `yaml:"disabled,omitempty"`
+	Budget   int     `yaml:"budget,omitempty"` // 首次返回前最多等待的时间 毫秒
+	Rerun    float64 `yaml:"rerun,omitempty"`  // Alfred 重新读取结果的间隔 秒(0.1~5.0)
+}
+
+// InstanceKey 返回服务实例标识，同一服务的不同配置(地址、密钥、目标语言等)标识不同
+func (c ConfigItem) InstanceKey() string {
+	data, _ := json.Marshal(c)
+	return c.Name + ":" + Md5(string(data))
+}
+
+// UsageKey 返回服务用量和额度统计的名称，设置了 usage_name 时使用该名称
+// 同名服务有多个时按账号(地址、密钥)区分，如 deepl:1a2b3c4d，同一账号的服务共用用量
+func (c *Config) UsageKey(item ConfigItem) string {
+	if item.UsageName != "" {
+		return item.UsageName
+	}
+	count := 0
+	for _, other := range c.Services {
+		if other.Name == item.Name {
+			count++
+		}
+	}
+	if count <= 1 {
+		return item.Name
+	}
+	account := Md5(strings.Join([]string{item.URL, item.Token, item.AppKey, item.AppSecret}, "\n"))
+	return item.Name + ":" + account[:8]
 }
 
 // GetConfigItemWithName 根据名称获取配置项
@@ -0,0 +1,296 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// deeplFreeAPIURL DeepL API Free 地址，免费版密钥以 :fx 结尾
+	deeplFreeAPIURL = "https://api-free.deepl.com"
+	// deeplProAPIURL DeepL API Pro 地址
+	deeplProAPIURL = "https://api.deepl.com"
+	// deeplUsageWait 翻译完成后等待用量查询的最长时间
+	deeplUsageWait = 300 * time.Millisecond
+)
+
+// deeplDefaultTargets 默认目标语言，选取第一个与源语言不同的目标
+var deeplDefaultTargets = []string{"ZH", "EN-US"}
+
+// deeplStatusKinds DeepL API 非200状态码对应的错误类型
+var deeplStatusKinds = map[int]ErrorKind{
+	http.StatusForbidden:       ErrorAuth,
+	http.StatusTooManyRequests: ErrorRateLimited,
+	456:                        ErrorQuota,
+}
+
+// deeplStatusMessages DeepL API 非200状态码说明
+var deeplStatusMessages = map[int]string{
+	http.StatusBadRequest:            "请求参数错误",
+	http.StatusForbidden:             "认证失败，请检查DeepL密钥",
+	http.StatusNotFound:              "资源不存在，请检查接口地址或术语表ID",
+	http.StatusRequestEntityTooLarge: "请求文本过长",
+	http.StatusTooManyRequests:       "请求过于频繁，请稍后重试",
+	456:                              "字符额度已用完",
+	http.StatusServiceUnavailable:    "服务暂时不可用",
+}
+
+// DeeplTranslationResult DeepL官方API翻译结果
+type DeeplTranslationResult struct {
+	Translations []struct {
+		DetectedSourceLanguage string `json:"detected_source_language"`
+		Text                   string `json:"text"`
+	} `json:"translations"`
+	Message string `json:"message,omitempty"`
+}
+
+// DeeplUsage DeepL官方API用量
+type DeeplUsage struct {
+	CharacterCount int64 `json:"character_count"`
+	CharacterLimit int64 `json:"character_limit"`
+}
+
+// DeeplService DeepL官方API翻译服务
+type DeeplService struct {
+	AuthKey     string
+	URL         string
+	Targets     []string
+	Formality   string // default/more/less/prefer_more/prefer_less
+	GlossaryID  string
+	TagHandling string       // html/xml，为空时不处理标签
+	Client      *http.Client // 为空时使用默认HTTP客户端
+	StateDir    string       // 保存自动创建的术语表ID的目录，为空时只在本进程内记录
+
+	glossaryStore *deeplGlossaryStore // 未设置 StateDir 时的术语表记录
+	usageCache    deeplUsageCache
+}
+
+// NewDeeplService 创建DeepL官方API翻译服务，根据密钥后缀选择Free或Pro地址
+func NewDeeplService(authKey string) *DeeplService {
+	serviceURL := deeplProAPIURL
+	if strings.HasSuffix(authKey, ":fx") {
+		serviceURL = deeplFreeAPIURL
+	}
+	return &DeeplService{
+		AuthKey: authKey,
+		URL:     serviceURL,
+		Targets: deeplDefaultTargets,
+	}
+}
+
+// Translate 使用DeepL官方API翻译
+func (s *DeeplService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
+	var results []TranslationResult
+
+	sourceLang, sourceKnown := deeplSourceLang(query)
+	targetLang := s.targetLang(sourceLang)
+
+	requestBody := map[string]interface{}{
+		"text":        []string{query},
+		"target_lang": targetLang,
+	}
+	if s.Formality != "" {
+		requestBody["formality"] = s.Formality
+	}
+	tagHandling := s.TagHandling
+	if kind := MarkupFromContext(ctx); tagHandling == "" && s.SupportsMarkup(kind) {
+		tagHandling = kind
+	}
+	if tagHandling != "" {
+		requestBody["tag_handling"] = tagHandling
+	}
+
+	// 用量缓存过期时查询用量，与翻译并发进行，失败时不影响翻译结果
+	usage := s.cachedUsage()
+	var usageChan chan *DeeplUsage
+	if usage == nil {
+		usageChan = make(chan *DeeplUsage, 1)
+		go func() {
+			usageChan <- s.refreshUsage(ctx)
+		}()
+	}
+
+	// 术语表绑定固定语言对，必须指定源语言，查询中没有术语时不使用术语表
+	// 不能从中日韩文字确定源语言时按字母推测(如只含英文字母视为英文)，仍无法判断时不使用术语表，不为识别语言额外发送请求
+	terms := GlossaryTermsFromContext(ctx)
+	useGlossary := s.GlossaryID != "" || len(glossaryTermsIn(query, terms)) > 0
+	if useGlossary && !sourceKnown {
+		sourceLang = strings.ToUpper(DetectLanguage(query))
+	}
+	var result *DeeplTranslationResult
+	if useGlossary && sourceLang != "" && !sameLanguage(sourceLang, targetLang) {
+		glossaryID := s.GlossaryID
+		if glossaryID != "" {
+			// 固定术语表只用于其自身的语言对
+			if source, target, err := s.glossaryLanguages(ctx, glossaryID); err != nil || !sameLanguage(source, sourceLang) || !sameLanguage(target, targetLang) {
+				glossaryID = ""
+			}
+		} else {
+			// 术语表创建失败(如语言对不支持)时不使用术语，不影响翻译
+			glossaryID, _ = s.EnsureGlossary(ctx, sourceLang, targetLang, terms)
+		}
+		if glossaryID != "" {
+			requestBody["glossary_id"] = glossaryID
+			requestBody["source_lang"] = sourceLang
+			glossed, err := s.translateText(ctx, requestBody)
+			switch {
+			case err == nil:
+				result = glossed
+			case s.GlossaryID != "":
+				return nil, err
+			default:
+				// 自动创建的术语表可能已被删除，下次重新查找，本次不使用术语
+				s.forgetGlossary(glossaryID)
+				delete(requestBody, "glossary_id")
+				delete(requestBody, "source_lang")
+			}
+		}
+	}
+	if result == nil {
+		plain, err := s.translateText(ctx, requestBody)
+		if err != nil {
+			return nil, err
+		}
+		result = plain
+	}
+
+	// 翻译完成后最多再等待 deeplUsageWait，避免用量查询拖慢结果
+	if usageChan != nil {
+		select {
+		case usage = <-usageChan:
+		case <-time.After(deeplUsageWait):
+		case <-ctx.Done():
+		}
+	}
+	usageText := ""
+	if usage != nil && usage.CharacterLimit > 0 {
+		usageText = fmt.Sprintf(" 已用%d/%d字符", usage.CharacterCount, usage.CharacterLimit)
+	}
+
+	for _, translation := range result.Translations {
+		text := translation.Text
+		if tagHandling == "" {
+			text = stripTags(query, text)
+		}
+		results = append(results, TranslationResult{
+			Title:    text,
+			Subtitle: fmt.Sprintf("DeepL翻译(%s→%s%s): %s", translation.DetectedSourceLanguage, targetLang, usageText, query),
+			Value:    text,
+			Provider: ProviderDeepl,
+
+			SourceLang: NormalizeLang(translation.DetectedSourceLanguage),
+		})
+	}
+
+	return results, nil
+}
+
+// translateText 调用翻译接口，结果为空时返回错误
+func (s *DeeplService) translateText(ctx context.Context, requestBody map[string]interface{}) (*DeeplTranslationResult, error) {
+	var result DeeplTranslationResult
+	if err := s.call(ctx, "POST", "/v2/translate", requestBody, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Translations) == 0 {
+		return nil, NewError(ProviderDeepl, ErrorBadResponse, "", "翻译结果为空")
+	}
+	return &result, nil
+}
+
+// deeplSourceLang 根据文字推测源语言，只有中文、日文、韩文能从文字确定
+// 其他文字(如只含拉丁字母)返回 EN 作为选取目标语言的依据，但不能作为术语表的源语言
+func deeplSourceLang(query string) (string, bool) {
+	switch lang := DetectLanguage(query); lang {
+	case "zh", "ja", "ko":
+		return strings.ToUpper(lang), true
+	}
+	return "EN", false
+}
+
+// SupportsMarkup DeepL 通过 tag_handling 直接翻译 HTML/XML
+func (s *DeeplService) SupportsMarkup(kind string) bool {
+	return kind == MarkupHTML || kind == MarkupXML
+}
+
+// Usage 查询当前计费周期的字符用量
+func (s *DeeplService) Usage(ctx context.Context) (*DeeplUsage, error) {
+	var usage DeeplUsage
+	if err := s.call(ctx, "GET", "/v2/usage", nil, &usage); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// targetLang 选取第一个与源语言不同的目标语言，支持 EN-GB、PT-BR、ZH-HANT 等地区变体
+func (s *DeeplService) targetLang(sourceLang string) string {
+	targets := s.Targets
+	if len(targets) == 0 {
+		targets = deeplDefaultTargets
+	}
+	for _, target := range targets {
+		if !sameLanguage(target, sourceLang) {
+			return strings.ToUpper(target)
+		}
+	}
+	return strings.ToUpper(targets[0])
+}
+
+// call 调用DeepL官方API，将响应解析到 out
+func (s *DeeplService) call(ctx context.Context, method, path string, requestBody interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if requestBody != nil {
+		jsonBody, err := json.Marshal(requestBody)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewBuffer(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(s.URL, "/")+path, reqBody)
+	if err != nil {
+		return err
+	}
+
+	if requestBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "DeepL-Auth-Key "+s.AuthKey)
+
+	status, body, err := doRequest(s.Client, req)
+	if err != nil {
+		return err
+	}
+
+	// 创建术语表返回201，删除返回204
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		msg, ok := deeplStatusMessages[status]
+		if !ok {
+			msg = "未知错误"
+		}
+		var result DeeplTranslationResult
+		if err := json.Unmarshal(body, &result); err == nil && result.Message != "" {
+			msg += ": " + result.Message
+		}
+		kind, ok := deeplStatusKinds[status]
+		if !ok {
+			kind = ErrorBadResponse
+		}
+		// 不支持的目标语言同样返回400，根据错误信息区分
+		if status == http.StatusBadRequest && strings.Contains(strings.ToLower(result.Message), "lang") {
+			kind = ErrorUnsupportedLanguage
+		}
+		return NewError(ProviderDeepl, kind, strconv.Itoa(status), msg)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return decodeJSON(status, body, out)
+}
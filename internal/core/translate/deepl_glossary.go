@@ -0,0 +1,240 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// deeplGlossaryPrefix 自动创建的术语表名称前缀
+const deeplGlossaryPrefix = "alfred-translate-"
+
+// DeeplGlossary DeepL术语表信息
+type DeeplGlossary struct {
+	GlossaryID string `json:"glossary_id"`
+	Name       string `json:"name"`
+	Ready      bool   `json:"ready"`
+	SourceLang string `json:"source_lang"`
+	TargetLang string `json:"target_lang"`
+	EntryCount int    `json:"entry_count"`
+}
+
+// deeplGlossaryStoreFile 保存安装标识和术语表ID的文件，位于服务状态目录
+const deeplGlossaryStoreFile = "deepl_glossaries.json"
+
+// deeplGlossaryStore 本机创建的术语表，跨进程保存，避免每次查询都列出账户下的术语表
+type deeplGlossaryStore struct {
+	InstallID  string            `json:"install_id"`
+	Glossaries map[string]string `json:"glossaries"`          // 账户标识/术语表名称 → ID
+	Languages  map[string]string `json:"languages,omitempty"` // 配置的术语表ID → 语言对，如 en-zh
+}
+
+// deeplGlossaryMu 保护本进程内对术语表记录文件的读写
+var deeplGlossaryMu sync.Mutex
+
+// SupportsGlossary 未配置固定术语表ID时，术语通过自动创建的DeepL术语表传入
+func (s *DeeplService) SupportsGlossary() bool {
+	return s.GlossaryID == ""
+}
+
+// Glossaries 列出账户下的所有术语表
+func (s *DeeplService) Glossaries(ctx context.Context) ([]DeeplGlossary, error) {
+	var result struct {
+		Glossaries []DeeplGlossary `json:"glossaries"`
+	}
+	if err := s.call(ctx, "GET", "/v2/glossaries", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Glossaries, nil
+}
+
+// EnsureGlossary 返回包含 terms 的语言对术语表ID，不存在时创建，并删除本机为同一语言对创建的旧版本
+// 术语表名称由安装标识、语言对和术语内容决定，术语不变时复用已有术语表
+// 其他设备或程序创建的术语表名称中的安装标识不同，不会被删除
+func (s *DeeplService) EnsureGlossary(ctx context.Context, sourceLang, targetLang string, terms []GlossaryTerm) (string, error) {
+	deeplGlossaryMu.Lock()
+	defer deeplGlossaryMu.Unlock()
+
+	store := s.loadGlossaryStore()
+	source := deeplGlossaryLang(sourceLang)
+	target := deeplGlossaryLang(targetLang)
+	entries := deeplGlossaryEntries(terms)
+	pairPrefix := deeplGlossaryPrefix + store.InstallID + "-" + source + "-" + target + "-"
+	name := pairPrefix + Md5(entries)[:12]
+	key := s.account() + "/" + name
+
+	if id, ok := store.Glossaries[key]; ok {
+		return id, nil
+	}
+
+	glossaries, err := s.Glossaries(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, glossary := range glossaries {
+		if glossary.Name == name && glossary.Ready {
+			store.Glossaries[key] = glossary.GlossaryID
+			s.saveGlossaryStore(store)
+			return glossary.GlossaryID, nil
+		}
+	}
+
+	var created DeeplGlossary
+	requestBody := map[string]interface{}{
+		"name":           name,
+		"source_lang":    source,
+		"target_lang":    target,
+		"entries":        entries,
+		"entries_format": "tsv",
+	}
+	if err := s.call(ctx, "POST", "/v2/glossaries", requestBody, &created); err != nil {
+		return "", err
+	}
+
+	// 术语变更后旧术语表不再使用，删除失败不影响翻译
+	for _, glossary := range glossaries {
+		if strings.HasPrefix(glossary.Name, pairPrefix) && glossary.Name != name {
+			_ = s.call(ctx, "DELETE", "/v2/glossaries/"+glossary.GlossaryID, nil, nil)
+			delete(store.Glossaries, s.account()+"/"+glossary.Name)
+		}
+	}
+	store.Glossaries[key] = created.GlossaryID
+	s.saveGlossaryStore(store)
+	return created.GlossaryID, nil
+}
+
+// glossaryLanguages 返回术语表的源语言和目标语言，如 en、zh
+// 配置的术语表ID第一次使用时查询，之后从术语表记录中读取
+func (s *DeeplService) glossaryLanguages(ctx context.Context, id string) (string, string, error) {
+	deeplGlossaryMu.Lock()
+	defer deeplGlossaryMu.Unlock()
+
+	store := s.loadGlossaryStore()
+	if pair, ok := store.Languages[id]; ok {
+		source, target, _ := strings.Cut(pair, "-")
+		return source, target, nil
+	}
+
+	var glossary DeeplGlossary
+	if err := s.call(ctx, "GET", "/v2/glossaries/"+id, nil, &glossary); err != nil {
+		return "", "", err
+	}
+	source := deeplGlossaryLang(glossary.SourceLang)
+	target := deeplGlossaryLang(glossary.TargetLang)
+	store.Languages[id] = source + "-" + target
+	s.saveGlossaryStore(store)
+	return source, target, nil
+}
+
+// forgetGlossary 删除记录的术语表ID，下次使用时重新查找或创建
+func (s *DeeplService) forgetGlossary(id string) {
+	deeplGlossaryMu.Lock()
+	defer deeplGlossaryMu.Unlock()
+
+	store := s.loadGlossaryStore()
+	for key, stored := range store.Glossaries {
+		if stored == id {
+			delete(store.Glossaries, key)
+		}
+	}
+	s.saveGlossaryStore(store)
+}
+
+// account 账户标识，术语表ID只在创建它的账户下有效，用量也按账户缓存
+func (s *DeeplService) account() string {
+	return Md5(s.AuthKey)[:12]
+}
+
+// loadGlossaryStore 读取术语表记录，没有记录时生成新的安装标识
+// 未设置状态目录时记录只保存在本进程内，安装标识由主机名和用户目录生成
+func (s *DeeplService) loadGlossaryStore() *deeplGlossaryStore {
+	if s.StateDir == "" {
+		if s.glossaryStore == nil {
+			host, _ := os.Hostname()
+			home, _ := os.UserHomeDir()
+			s.glossaryStore = &deeplGlossaryStore{InstallID: Md5(host + home)[:8], Glossaries: map[string]string{}, Languages: map[string]string{}}
+		}
+		return s.glossaryStore
+	}
+
+	store := &deeplGlossaryStore{}
+	if data, err := os.ReadFile(filepath.Join(s.StateDir, deeplGlossaryStoreFile)); err == nil {
+		_ = json.Unmarshal(data, store)
+	}
+	if store.InstallID == "" {
+		store.InstallID = strings.ReplaceAll(NewUUID(), "-", "")[:8]
+		s.saveGlossaryStore(store)
+	}
+	if store.Glossaries == nil {
+		store.Glossaries = map[string]string{}
+	}
+	if store.Languages == nil {
+		store.Languages = map[string]string{}
+	}
+	return store
+}
+
+// saveGlossaryStore 写入术语表记录，先写入临时文件再重命名，写入失败时下次重新查找
+func (s *DeeplService) saveGlossaryStore(store *deeplGlossaryStore) {
+	if s.StateDir == "" {
+		return
+	}
+	data, err := json.Marshal(store)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(s.StateDir, 0o755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(s.StateDir, "deepl-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	_ = os.Rename(tmp.Name(), filepath.Join(s.StateDir, deeplGlossaryStoreFile))
+}
+
+// glossaryTermsIn 返回查询中出现的术语
+func glossaryTermsIn(query string, terms []GlossaryTerm) []GlossaryTerm {
+	var found []GlossaryTerm
+	for _, term := range terms {
+		if re := termPattern(term.Source, true); re != nil && re.MatchString(query) {
+			found = append(found, term)
+		}
+	}
+	return found
+}
+
+// deeplGlossaryLang 术语表使用不带地区的小写语言代码，如 EN-US → en
+func deeplGlossaryLang(lang string) string {
+	lang = strings.ToLower(lang)
+	if i := strings.Index(lang, "-"); i >= 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
+
+// deeplGlossaryEntries 将术语转换为 TSV 格式，包含制表符或换行的术语无法表示，跳过
+func deeplGlossaryEntries(terms []GlossaryTerm) string {
+	lines := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if strings.ContainsAny(term.Source+term.Target, "\t\r\n") {
+			continue
+		}
+		lines = append(lines, term.Source+"\t"+term.Target)
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
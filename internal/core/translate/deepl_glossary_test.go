@@ -0,0 +1,224 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDeepl 模拟 DeepL 术语表和翻译接口
+type fakeDeepl struct {
+	mu         sync.Mutex
+	glossaries []DeeplGlossary
+	calls      []string                 // 方法和路径
+	translates []map[string]interface{} // 翻译请求体
+	detected   string                   // 翻译接口返回的源语言
+}
+
+func newFakeDeepl(t *testing.T, detected string, glossaries ...DeeplGlossary) (*fakeDeepl, *httptest.Server) {
+	t.Helper()
+	fake := &fakeDeepl{glossaries: glossaries, detected: detected}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		fake.calls = append(fake.calls, r.Method+" "+r.URL.Path)
+
+		var body map[string]interface{}
+		if r.Body != nil {
+			_ = json.NewDecoder(r.Body).Decode(&body)
+		}
+		switch {
+		case r.URL.Path == "/v2/usage":
+			json.NewEncoder(w).Encode(DeeplUsage{})
+		case r.URL.Path == "/v2/translate":
+			fake.translates = append(fake.translates, body)
+			text := "plain"
+			if body["glossary_id"] != nil {
+				text = "glossary"
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"translations": []map[string]string{{"detected_source_language": fake.detected, "text": text}},
+			})
+		case r.URL.Path == "/v2/glossaries" && r.Method == "GET":
+			json.NewEncoder(w).Encode(map[string]interface{}{"glossaries": fake.glossaries})
+		case r.URL.Path == "/v2/glossaries" && r.Method == "POST":
+			created := DeeplGlossary{
+				GlossaryID: "id-" + body["name"].(string),
+				Name:       body["name"].(string),
+				Ready:      true,
+			}
+			fake.glossaries = append(fake.glossaries, created)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(created)
+		case strings.HasPrefix(r.URL.Path, "/v2/glossaries/") && r.Method == "GET":
+			id := strings.TrimPrefix(r.URL.Path, "/v2/glossaries/")
+			for _, glossary := range fake.glossaries {
+				if glossary.GlossaryID == id {
+					json.NewEncoder(w).Encode(glossary)
+					return
+				}
+			}
+			http.NotFound(w, r)
+		case strings.HasPrefix(r.URL.Path, "/v2/glossaries/") && r.Method == "DELETE":
+			id := strings.TrimPrefix(r.URL.Path, "/v2/glossaries/")
+			for i, glossary := range fake.glossaries {
+				if glossary.GlossaryID == id {
+					fake.glossaries = append(fake.glossaries[:i], fake.glossaries[i+1:]...)
+					break
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+// count 返回指定请求的次数
+func (f *fakeDeepl) count(call string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, c := range f.calls {
+		if c == call {
+			n++
+		}
+	}
+	return n
+}
+
+func newTestDeeplService(url, stateDir string) *DeeplService {
+	service := NewDeeplService("key")
+	service.URL = url
+	service.StateDir = stateDir
+	return service
+}
+
+func TestEnsureGlossaryPersistsIDs(t *testing.T) {
+	fake, server := newFakeDeepl(t, "EN")
+	dir := t.TempDir()
+	terms := []GlossaryTerm{{Source: "cache", Target: "缓存"}}
+
+	id, err := newTestDeeplService(server.URL, dir).EnsureGlossary(context.Background(), "EN", "ZH", terms)
+	if err != nil || id == "" {
+		t.Fatalf("EnsureGlossary() = %q, %v", id, err)
+	}
+	// 新进程中的服务从状态目录读取术语表ID，不再列出术语表
+	again, err := newTestDeeplService(server.URL, dir).EnsureGlossary(context.Background(), "EN", "ZH", terms)
+	if err != nil || again != id {
+		t.Errorf("EnsureGlossary() again = %q, %v, want %q", again, err, id)
+	}
+	if n := fake.count("GET /v2/glossaries"); n != 1 {
+		t.Errorf("GET /v2/glossaries called %d times, want 1", n)
+	}
+	if n := fake.count("POST /v2/glossaries"); n != 1 {
+		t.Errorf("POST /v2/glossaries called %d times, want 1", n)
+	}
+}
+
+func TestEnsureGlossaryDeletesOnlyOwnGlossaries(t *testing.T) {
+	dir := t.TempDir()
+	// 先确定本机的安装标识
+	installID := newTestDeeplService("", dir).loadGlossaryStore().InstallID
+	own := DeeplGlossary{GlossaryID: "own", Name: deeplGlossaryPrefix + installID + "-en-zh-000000000000", Ready: true}
+	other := DeeplGlossary{GlossaryID: "other", Name: deeplGlossaryPrefix + "otherid0-en-zh-000000000000", Ready: true}
+	legacy := DeeplGlossary{GlossaryID: "legacy", Name: deeplGlossaryPrefix + "en-zh-000000000000", Ready: true}
+	fake, server := newFakeDeepl(t, "EN", own, other, legacy)
+
+	_, err := newTestDeeplService(server.URL, dir).EnsureGlossary(context.Background(), "EN", "ZH", []GlossaryTerm{{Source: "cache", Target: "缓存"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := fake.count("DELETE /v2/glossaries/own"); n != 1 {
+		t.Errorf("own glossary deleted %d times, want 1", n)
+	}
+	if fake.count("DELETE /v2/glossaries/other")+fake.count("DELETE /v2/glossaries/legacy") != 0 {
+		t.Errorf("deleted glossaries of other installations: %v", fake.calls)
+	}
+}
+
+func TestDeeplGlossarySourceLang(t *testing.T) {
+	terms := []GlossaryTerm{{Source: "cache", Target: "缓存"}, {Source: "缓存", Target: "cache"}}
+	tests := []struct {
+		name       string
+		query      string
+		detected   string
+		wantSource []interface{} // 每次翻译请求的 source_lang
+		want       string
+	}{
+		{name: "中文直接使用术语表", query: "清空缓存", detected: "ZH", wantSource: []interface{}{"ZH"}, want: "glossary"},
+		{name: "只含英文字母按英文使用术语表", query: "clear the cache", detected: "EN", wantSource: []interface{}{"EN"}, want: "glossary"},
+		// 无法判断源语言时不使用术语表，也不为识别语言多发送一次请求
+		{name: "无法判断源语言", query: "vider le cache à café", detected: "FR", wantSource: []interface{}{nil}, want: "plain"},
+		{name: "没有术语时不使用术语表", query: "hello world", detected: "EN", wantSource: []interface{}{nil}, want: "plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, server := newFakeDeepl(t, tt.detected)
+			service := newTestDeeplService(server.URL, t.TempDir())
+			ctx := WithGlossaryTerms(context.Background(), terms)
+
+			results, err := service.Translate(ctx, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 1 || results[0].Value != tt.want {
+				t.Errorf("results = %+v, want %s", results, tt.want)
+			}
+			if len(fake.translates) != len(tt.wantSource) {
+				t.Fatalf("translate requests = %v, want %d", fake.translates, len(tt.wantSource))
+			}
+			for i, body := range fake.translates {
+				if body["source_lang"] != tt.wantSource[i] {
+					t.Errorf("request %d source_lang = %v, want %v", i, body["source_lang"], tt.wantSource[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDeeplFixedGlossaryLanguages(t *testing.T) {
+	fake, server := newFakeDeepl(t, "EN", DeeplGlossary{GlossaryID: "fixed", Name: "team", Ready: true, SourceLang: "en", TargetLang: "zh"})
+	dir := t.TempDir()
+	tests := []struct {
+		query      string
+		wantSource interface{}
+		want       string
+	}{
+		{query: "clear the cache", wantSource: "EN", want: "glossary"},
+		// 中文查询与术语表的语言对不符，不使用术语表
+		{query: "清空缓存", wantSource: nil, want: "plain"},
+		{query: "hello world", wantSource: "EN", want: "glossary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			service := newTestDeeplService(server.URL, dir)
+			service.GlossaryID = "fixed"
+			before := len(fake.translates)
+
+			results, err := service.Translate(context.Background(), tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 1 || results[0].Value != tt.want {
+				t.Errorf("results = %+v, want %s", results, tt.want)
+			}
+			if n := len(fake.translates) - before; n != 1 {
+				t.Fatalf("translate requests = %d, want 1", n)
+			}
+			if got := fake.translates[before]["source_lang"]; got != tt.wantSource {
+				t.Errorf("source_lang = %v, want %v", got, tt.wantSource)
+			}
+		})
+	}
+	// 术语表的语言对只查询一次
+	if n := fake.count("GET /v2/glossaries/fixed"); n != 1 {
+		t.Errorf("GET /v2/glossaries/fixed called %d times, want 1", n)
+	}
+}
@@ -0,0 +1,108 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// deeplUsageTTL 用量的缓存时间，期间的翻译不再查询用量
+	deeplUsageTTL = 5 * time.Minute
+	// deeplUsageFile 保存用量的文件，位于服务状态目录，按账户记录
+	deeplUsageFile = "deepl_usage.json"
+)
+
+// deeplUsageRecord 缓存的用量和查询时间
+type deeplUsageRecord struct {
+	DeeplUsage
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// deeplUsageCache 本进程内的用量缓存，同一账户同时只有一个用量查询
+type deeplUsageCache struct {
+	mu       sync.Mutex
+	record   *deeplUsageRecord
+	fetching bool
+}
+
+// cachedUsage 返回未过期的用量，先读取本进程内的缓存，再读取其他进程保存在状态目录中的记录
+func (s *DeeplService) cachedUsage() *DeeplUsage {
+	s.usageCache.mu.Lock()
+	defer s.usageCache.mu.Unlock()
+
+	if s.usageCache.record == nil && s.StateDir != "" {
+		records := map[string]deeplUsageRecord{}
+		if data, err := os.ReadFile(filepath.Join(s.StateDir, deeplUsageFile)); err == nil {
+			_ = json.Unmarshal(data, &records)
+		}
+		if record, ok := records[s.account()]; ok {
+			s.usageCache.record = &record
+		}
+	}
+	if record := s.usageCache.record; record != nil && time.Since(record.FetchedAt) < deeplUsageTTL {
+		usage := record.DeeplUsage
+		return &usage
+	}
+	return nil
+}
+
+// refreshUsage 查询用量并缓存，已有查询在进行或查询失败时返回 nil
+func (s *DeeplService) refreshUsage(ctx context.Context) *DeeplUsage {
+	s.usageCache.mu.Lock()
+	if s.usageCache.fetching {
+		s.usageCache.mu.Unlock()
+		return nil
+	}
+	s.usageCache.fetching = true
+	s.usageCache.mu.Unlock()
+
+	usage, err := s.Usage(ctx)
+
+	s.usageCache.mu.Lock()
+	defer s.usageCache.mu.Unlock()
+	s.usageCache.fetching = false
+	if err != nil {
+		return nil
+	}
+	s.usageCache.record = &deeplUsageRecord{DeeplUsage: *usage, FetchedAt: time.Now()}
+	s.saveUsage(*s.usageCache.record)
+	return usage
+}
+
+// saveUsage 写入用量记录，先写入临时文件再重命名，写入失败时下次重新查询
+func (s *DeeplService) saveUsage(record deeplUsageRecord) {
+	if s.StateDir == "" {
+		return
+	}
+	path := filepath.Join(s.StateDir, deeplUsageFile)
+	records := map[string]deeplUsageRecord{}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &records)
+	}
+	records[s.account()] = record
+	data, err := json.Marshal(records)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(s.StateDir, 0o755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(s.StateDir, "deepl-usage-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	_ = os.Rename(tmp.Name(), path)
+}
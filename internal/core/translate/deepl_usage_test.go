@@ -0,0 +1,47 @@
+package translate
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDeeplUsageCached(t *testing.T) {
+	fake, server := newFakeDeepl(t, "EN")
+	dir := t.TempDir()
+
+	service := newTestDeeplService(server.URL, dir)
+	for _, query := range []string{"hello", "world"} {
+		if _, err := service.Translate(context.Background(), query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 新进程中的服务读取状态目录中的用量记录
+	if _, err := newTestDeeplService(server.URL, dir).Translate(context.Background(), "again"); err != nil {
+		t.Fatal(err)
+	}
+	if n := fake.count("GET /v2/usage"); n != 1 {
+		t.Errorf("GET /v2/usage called %d times, want 1", n)
+	}
+	if n := fake.count("POST /v2/translate"); n != 3 {
+		t.Errorf("POST /v2/translate called %d times, want 3", n)
+	}
+}
+
+func TestDeeplUsageExpired(t *testing.T) {
+	fake, server := newFakeDeepl(t, "EN")
+	service := newTestDeeplService(server.URL, "")
+	if service.refreshUsage(context.Background()) == nil {
+		t.Fatal("refreshUsage() = nil")
+	}
+	if service.cachedUsage() == nil {
+		t.Fatal("cachedUsage() after refresh = nil")
+	}
+
+	service.usageCache.record.FetchedAt = service.usageCache.record.FetchedAt.Add(-deeplUsageTTL)
+	if usage := service.cachedUsage(); usage != nil {
+		t.Errorf("cachedUsage() after TTL = %+v, want nil", usage)
+	}
+	if n := fake.count("GET /v2/usage"); n != 1 {
+		t.Errorf("GET /v2/usage called %d times, want 1", n)
+	}
+}
@@ -0,0 +1,513 @@
+package translate
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	// dictSuggestionLimit 联想词数量上限
+	dictSuggestionLimit = 5
+	// dictTranslationLimit 每个词条展示的中文释义数量上限
+	dictTranslationLimit = 6
+	// dictSpeechURL 单词发音地址(美式)，离线时使用已缓存的音频或本地TTS
+	dictSpeechURL = "https://dict.youdao.com/dictvoice?type=2&audio="
+)
+
+// dictExamTags ECDICT 考试标签
+var dictExamTags = map[string]string{
+	"zk":    "中考",
+	"gk":    "高考",
+	"cet4":  "四级",
+	"cet6":  "六级",
+	"ky":    "考研",
+	"toefl": "托福",
+	"ielts": "雅思",
+	"gre":   "GRE",
+}
+
+// dictExchangeNames ECDICT 词形变化类型
+var dictExchangeNames = []struct {
+	code string
+	name string
+}{
+	{"s", "复数"},
+	{"3", "第三人称单数"},
+	{"p", "过去式"},
+	{"d", "过去分词"},
+	{"i", "现在分词"},
+	{"r", "比较级"},
+	{"t", "最高级"},
+}
+
+// DictEntry 本地词典词条
+type DictEntry struct {
+	Word        string `json:"word"`
+	Phonetic    string `json:"phonetic,omitempty"`
+	Definition  string `json:"definition,omitempty"`  // 英文释义，多条以换行分隔
+	Translation string `json:"translation,omitempty"` // 中文释义，多条以换行分隔
+	Tag         string `json:"tag,omitempty"`         // 考试标签，空格分隔，如 cet4 cet6
+	Exchange    string `json:"exchange,omitempty"`    // 词形变化，如 p:ran/d:run/i:running/3:runs
+}
+
+// Exams 返回考试标签的中文名称，如 四级 六级
+func (e *DictEntry) Exams() string {
+	var exams []string
+	for _, tag := range strings.Fields(e.Tag) {
+		if name, ok := dictExamTags[tag]; ok {
+			exams = append(exams, name)
+		}
+	}
+	return strings.Join(exams, " ")
+}
+
+// exchanges 解析词形变化，返回 类型 → 词形
+func (e *DictEntry) exchanges() map[string]string {
+	forms := map[string]string{}
+	for _, part := range strings.Split(e.Exchange, "/") {
+		if kind, value, ok := strings.Cut(part, ":"); ok && value != "" {
+			forms[kind] = value
+		}
+	}
+	return forms
+}
+
+// Lemma 返回词条的原形，词条本身是原形时返回空
+func (e *DictEntry) Lemma() string {
+	lemma := e.exchanges()["0"]
+	if strings.EqualFold(lemma, e.Word) {
+		return ""
+	}
+	return lemma
+}
+
+// WordForms 返回格式化的词形变化，如 过去式: ran  现在分词: running
+func (e *DictEntry) WordForms() string {
+	forms := e.exchanges()
+	var parts []string
+	for _, exchange := range dictExchangeNames {
+		if value, ok := forms[exchange.code]; ok {
+			parts = append(parts, exchange.name+": "+value)
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+// Translations 返回中文释义列表
+func (e *DictEntry) Translations() []string {
+	var lines []string
+	for _, line := range strings.Split(e.Translation, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// DictService 离线英汉词典服务，支持 ECDICT 格式的 CSV 和 StarDict 词典
+// 首次使用时建立索引文件(词典文件名.index)，之后直接在索引文件中查找
+type DictService struct {
+	Path      string
+	IndexPath string
+
+	mu    sync.Mutex
+	index *DictIndex
+}
+
+// NewDictService 创建离线词典服务，StarDict 词典指定 .ifo 文件
+func NewDictService(path string) *DictService {
+	return &DictService{
+		Path:      path,
+		IndexPath: path + ".index",
+	}
+}
+
+// Local 离线词典不访问网络
+func (s *DictService) Local() bool {
+	return true
+}
+
+// ErrDictIndexNotReady 索引不存在或词典文件已更新，需要先运行 BuildIndex
+var ErrDictIndexNotReady = errors.New("dict error: index not ready")
+
+// dictCheckInterval 读取词典和建立索引时每处理这么多条检查一次是否已取消
+const dictCheckInterval = 10000
+
+// Index 打开索引，索引不存在或词典文件已更新时返回 ErrDictIndexNotReady
+// 建立索引需要读取整个词典，由 BuildIndex 在查询之外完成
+func (s *DictService) Index() (*DictIndex, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.index != nil {
+		return s.index, nil
+	}
+
+	source, err := os.Stat(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	index, ok, err := OpenDictIndex(s.IndexPath, source)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrDictIndexNotReady
+	}
+	s.index = index
+	return index, nil
+}
+
+// IndexReady 索引是否存在且与词典文件一致
+func (s *DictService) IndexReady() bool {
+	source, err := os.Stat(s.Path)
+	if err != nil {
+		return false
+	}
+	index, ok, err := OpenDictIndex(s.IndexPath, source)
+	if err != nil || !ok {
+		return false
+	}
+	index.Close()
+	return true
+}
+
+// BuildIndex 读取词典文件建立索引，索引已是最新时直接返回，ctx 取消时停止
+func (s *DictService) BuildIndex(ctx context.Context) error {
+	source, err := os.Stat(s.Path)
+	if err != nil {
+		return err
+	}
+	if s.IndexReady() {
+		return nil
+	}
+	return s.build(ctx, source)
+}
+
+// build 读取词典文件建立索引
+func (s *DictService) build(ctx context.Context, source os.FileInfo) error {
+	var entries []DictEntry
+	var err error
+	switch strings.ToLower(filepath.Ext(s.Path)) {
+	case ".ifo":
+		entries, err = loadStarDict(ctx, s.Path)
+	default:
+		entries, err = loadECDict(ctx, s.Path)
+	}
+	if err != nil {
+		return err
+	}
+
+	records := make([]dictRecord, 0, len(entries)*2)
+	for i, entry := range entries {
+		if i%dictCheckInterval == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		record, err := entryRecord(entry)
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+
+		// 原形的词形变化指向原形，用于查找 ran → run
+		if entry.Lemma() != "" {
+			continue
+		}
+		forms := entry.exchanges()
+		for _, exchange := range dictExchangeNames {
+			if form, ok := forms[exchange.code]; ok {
+				records = append(records, dictRecord{key: dictKey(form), kind: dictRecordLemma, payload: entry.Word})
+			}
+		}
+	}
+	return WriteDictIndex(s.IndexPath, source, records)
+}
+
+// Entries 查找单词的词条
+func (s *DictService) Entries(word string) ([]DictEntry, error) {
+	index, err := s.Index()
+	if err != nil {
+		return nil, err
+	}
+	records, err := index.Lookup(dictKey(word))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []DictEntry
+	for _, record := range records {
+		if record.kind != dictRecordEntry {
+			continue
+		}
+		var entry DictEntry
+		if err := json.Unmarshal([]byte(record.payload), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// Lemmas 返回单词可能的原形，优先使用词典中的词形变化，其次按构词规则推测
+func (s *DictService) Lemmas(word string) ([]string, error) {
+	index, err := s.Index()
+	if err != nil {
+		return nil, err
+	}
+	key := dictKey(word)
+
+	var lemmas []string
+	records, err := index.Lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		if record.kind == dictRecordLemma && !containsString(lemmas, record.payload) {
+			lemmas = append(lemmas, record.payload)
+		}
+	}
+	if len(lemmas) > 0 {
+		return lemmas, nil
+	}
+
+	// 词典中有该词时不再推测，避免 sing → s 之类的误判
+	if entries, err := s.Entries(key); err != nil || len(entries) > 0 {
+		return nil, err
+	}
+	for _, candidate := range GuessLemmas(key) {
+		entries, err := s.Entries(candidate)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) > 0 {
+			lemmas = append(lemmas, entries[0].Word)
+			break
+		}
+	}
+	return lemmas, nil
+}
+
+// Suggestions 返回以 prefix 开头的单词，不含 prefix 本身
+func (s *DictService) Suggestions(prefix string, limit int) ([]DictEntry, error) {
+	index, err := s.Index()
+	if err != nil {
+		return nil, err
+	}
+	key := dictKey(prefix)
+	records, err := index.Prefix(key, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []DictEntry
+	for _, record := range records {
+		if record.key == key {
+			continue
+		}
+		var entry DictEntry
+		if err := json.Unmarshal([]byte(record.payload), &entry); err == nil && len(entries) < limit {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// Translate 查询单个英文单词，依次返回词条(或原形的词条)和联想词，非英文单词返回空结果
+func (s *DictService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
+	word := strings.TrimSpace(query)
+	if !IsSingleWord(word) || HasChineseChar(word) {
+		return nil, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	entries, err := s.Entries(word)
+	if errors.Is(err, ErrDictIndexNotReady) {
+		e := NewError(ProviderDict, ErrorUnknown, "", "正在建立词典索引，请稍后重试")
+		e.Err = err
+		return nil, e
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var results []TranslationResult
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		results = append(results, entry.Results(query)...)
+		seen[strings.ToLower(entry.Word)] = true
+	}
+
+	// running → run：词形变化同时展示原形的释义
+	lemmas, err := s.Lemmas(word)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if lemma := entry.Lemma(); lemma != "" && !containsString(lemmas, lemma) {
+			lemmas = append(lemmas, lemma)
+		}
+	}
+	for _, lemma := range lemmas {
+		if seen[strings.ToLower(lemma)] {
+			continue
+		}
+		seen[strings.ToLower(lemma)] = true
+		lemmaEntries, err := s.Entries(lemma)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range lemmaEntries {
+			for _, result := range entry.Results(query) {
+				result.Subtitle = fmt.Sprintf("%s → %s", word, entry.Word) + " " + result.Subtitle
+				results = append(results, result)
+			}
+		}
+	}
+
+	suggestions, err := s.Suggestions(word, dictSuggestionLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range suggestions {
+		translations := entry.Translations()
+		if len(translations) == 0 {
+			continue
+		}
+		results = append(results, TranslationResult{
+			Title:    entry.Word + ": " + translations[0],
+			Subtitle: "本地词典 联想: " + query,
+			Value:    entry.Word,
+			Provider: ProviderDict,
+		})
+	}
+
+	return results, ctx.Err()
+}
+
+// Results 将词条的音标、中文释义、英文释义和词形变化转换为结果项
+func (e *DictEntry) Results(query string) []TranslationResult {
+	var results []TranslationResult
+
+	subtitle := "本地词典"
+	if exams := e.Exams(); exams != "" {
+		subtitle += " (" + exams + ")"
+	}
+
+	if e.Phonetic != "" {
+		phonetic := "[" + e.Phonetic + "]"
+		results = append(results, TranslationResult{
+			Title:    e.Word + " " + phonetic,
+			Subtitle: subtitle + " 音标: " + query,
+			Value:    phonetic,
+			Provider: ProviderDict,
+			Speech:   &Speech{Text: e.Word, Voice: "us", URL: dictSpeechURL + url.QueryEscape(e.Word)},
+		})
+	}
+
+	for i, translation := range e.Translations() {
+		if i >= dictTranslationLimit {
+			break
+		}
+		results = append(results, TranslationResult{
+			Title:    translation,
+			Subtitle: subtitle + " 释义: " + query,
+			Value:    translation,
+			Provider: ProviderDict,
+		})
+	}
+
+	if definition := strings.TrimSpace(strings.SplitN(e.Definition, "\n", 2)[0]); definition != "" {
+		results = append(results, TranslationResult{
+			Title:    definition,
+			Subtitle: subtitle + " 英文释义: " + query,
+			Value:    definition,
+			Provider: ProviderDict,
+		})
+	}
+
+	if wordForms := e.WordForms(); wordForms != "" {
+		results = append(results, TranslationResult{
+			Title:    wordForms,
+			Subtitle: subtitle + " 词形: " + query,
+			Value:    wordForms,
+			Provider: ProviderDict,
+		})
+	}
+
+	return results
+}
+
+// LoadECDict 读取 ECDICT 格式的 CSV 词典，按首行列名识别 word、phonetic、translation 等列
+// 字段中的换行以 \n 转义
+func LoadECDict(path string) ([]DictEntry, error) {
+	return loadECDict(context.Background(), path)
+}
+
+// loadECDict 读取 ECDICT 词典，ctx 取消时停止
+func loadECDict(ctx context.Context, path string) ([]DictEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("dict error: %s: %w", path, err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["word"]; !ok {
+		return nil, fmt.Errorf("dict error: %s: missing word column", path)
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(strings.ReplaceAll(record[i], `\n`, "\n"))
+	}
+
+	var entries []DictEntry
+	for {
+		if len(entries)%dictCheckInterval == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("dict error: %s: %w", path, err)
+		}
+		entry := DictEntry{
+			Word:        field(record, "word"),
+			Phonetic:    field(record, "phonetic"),
+			Definition:  field(record, "definition"),
+			Translation: field(record, "translation"),
+			Tag:         field(record, "tag"),
+			Exchange:    field(record, "exchange"),
+		}
+		if entry.Word != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,243 @@
+package translate
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// dictIndexMagic 索引文件首行标识，之后是源文件大小和修改时间，源文件变化时重建索引
+const dictIndexMagic = "#translate-dict-index v1"
+
+// 索引记录类型
+const (
+	dictRecordEntry = "e" // 词条，内容为 DictEntry 的 JSON
+	dictRecordLemma = "l" // 词形变化，内容为原形
+)
+
+// dictIndexSearchWindow 二分查找缩小到该范围后顺序扫描
+const dictIndexSearchWindow = 8 << 10
+
+// DictIndex 按键排序的文本索引文件，每行 键\t类型\t内容，通过二分查找定位，无需全部读入内存
+type DictIndex struct {
+	file  *os.File
+	start int64 // 首条记录的偏移
+	size  int64
+}
+
+// dictRecord 索引中的一条记录
+type dictRecord struct {
+	key     string
+	kind    string
+	payload string
+}
+
+// dictIndexHeader 返回源文件对应的索引首行
+func dictIndexHeader(source os.FileInfo) string {
+	return fmt.Sprintf("%s %d %d", dictIndexMagic, source.Size(), source.ModTime().UnixNano())
+}
+
+// OpenDictIndex 打开索引文件，索引不存在或与源文件不一致时返回 false
+func OpenDictIndex(path string, source os.FileInfo) (*DictIndex, bool, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	header, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil || strings.TrimSuffix(header, "\n") != dictIndexHeader(source) {
+		file.Close()
+		return nil, false, nil
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, false, err
+	}
+	return &DictIndex{file: file, start: int64(len(header)), size: info.Size()}, true, nil
+}
+
+// WriteDictIndex 排序记录并写入索引文件，先写临时文件再重命名
+func WriteDictIndex(path string, source os.FileInfo, records []dictRecord) error {
+	lines := make([]string, 0, len(records))
+	for _, record := range records {
+		if record.key == "" {
+			continue
+		}
+		lines = append(lines, record.key+"\t"+record.kind+"\t"+record.payload)
+	}
+	sort.Strings(lines)
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	fmt.Fprintln(w, dictIndexHeader(source))
+	previous := ""
+	for _, line := range lines {
+		if line == previous {
+			continue
+		}
+		previous = line
+		w.WriteString(line)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Close 关闭索引文件
+func (idx *DictIndex) Close() error {
+	return idx.file.Close()
+}
+
+// Lookup 返回键等于 key 的所有记录
+func (idx *DictIndex) Lookup(key string) ([]dictRecord, error) {
+	var records []dictRecord
+	err := idx.scan(key, func(record dictRecord) bool {
+		if record.key != key {
+			return false
+		}
+		records = append(records, record)
+		return true
+	})
+	return records, err
+}
+
+// Prefix 返回以 prefix 开头的词条，按键排序，最多 limit 条
+func (idx *DictIndex) Prefix(prefix string, limit int) ([]dictRecord, error) {
+	var records []dictRecord
+	err := idx.scan(prefix, func(record dictRecord) bool {
+		if !strings.HasPrefix(record.key, prefix) || len(records) >= limit {
+			return false
+		}
+		if record.kind == dictRecordEntry {
+			records = append(records, record)
+		}
+		return true
+	})
+	return records, err
+}
+
+// scan 从第一条键不小于 key 的记录开始顺序读取，fn 返回 false 时停止
+func (idx *DictIndex) scan(key string, fn func(dictRecord) bool) error {
+	offset, err := idx.lowerBound(key)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(idx.file, offset, idx.size-offset))
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			record := parseDictRecord(strings.TrimSuffix(line, "\n"))
+			if record.key >= key && !fn(record) {
+				return nil
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// lowerBound 二分查找第一条键不小于 key 的记录附近的行首偏移
+// 返回的偏移之前的记录键都小于 key
+func (idx *DictIndex) lowerBound(key string) (int64, error) {
+	lo, hi := idx.start, idx.size
+	for hi-lo > dictIndexSearchWindow {
+		mid := lo + (hi-lo)/2
+		lineStart, line, err := idx.lineAfter(mid)
+		if err != nil {
+			return 0, err
+		}
+		if lineStart >= hi {
+			hi = mid
+			continue
+		}
+		if parseDictRecord(line).key < key {
+			lo = lineStart + int64(len(line)) + 1
+		} else {
+			hi = lineStart
+		}
+	}
+	return lo, nil
+}
+
+// lineAfter 返回 offset 之后(含)第一个完整行的行首偏移和内容
+func (idx *DictIndex) lineAfter(offset int64) (int64, string, error) {
+	reader := bufio.NewReader(io.NewSectionReader(idx.file, offset-1, idx.size-offset+1))
+	skipped, err := reader.ReadBytes('\n')
+	if err == io.EOF {
+		return idx.size, "", nil
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	lineStart := offset - 1 + int64(len(skipped))
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return 0, "", err
+	}
+	return lineStart, strings.TrimSuffix(line, "\n"), nil
+}
+
+// parseDictRecord 解析索引行
+func parseDictRecord(line string) dictRecord {
+	parts := strings.SplitN(line, "\t", 3)
+	for len(parts) < 3 {
+		parts = append(parts, "")
+	}
+	return dictRecord{key: parts[0], kind: parts[1], payload: parts[2]}
+}
+
+// dictKey 索引键，小写并去除首尾空白，控制字符替换为空格以免破坏行格式
+func dictKey(word string) string {
+	word = strings.Map(func(r rune) rune {
+		if r < ' ' {
+			return ' '
+		}
+		return r
+	}, word)
+	return strings.ToLower(strings.TrimSpace(word))
+}
+
+// entryRecord 将词条转换为索引记录
+func entryRecord(entry DictEntry) (dictRecord, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(entry); err != nil {
+		return dictRecord{}, err
+	}
+	return dictRecord{
+		key:     dictKey(entry.Word),
+		kind:    dictRecordEntry,
+		payload: strings.TrimSuffix(buf.String(), "\n"),
+	}, nil
+}
@@ -0,0 +1,256 @@
+package translate
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// dictTestFillers 填充词条数量，使索引远大于 dictIndexSearchWindow，覆盖二分查找
+const dictTestFillers = 2000
+
+// newTestDict 生成小型 ECDICT 词典并建立索引
+func newTestDict(t *testing.T) *DictService {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ecdict.csv")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := csv.NewWriter(file)
+	w.Write([]string{"word", "phonetic", "definition", "translation", "tag", "exchange"})
+	w.Write([]string{"apple", "'æpl", "n. fruit", "n. 苹果", "zk gk", "s:apples"})
+	w.Write([]string{"go", "gәu", "v. move", "v. 去", "zk", "p:went/d:gone/i:going/3:goes"})
+	w.Write([]string{"went", "went", "", "v. go 的过去式", "", "0:go"})
+	w.Write([]string{"box", "bɔks", "n. container", "n. 盒子", "", ""})
+	w.Write([]string{"zebra", "'zi:brә", "n. animal", "n. 斑马", "", ""})
+	for i := 0; i < dictTestFillers; i++ {
+		w.Write([]string{fmt.Sprintf("word%04d", i), "", "", fmt.Sprintf("n. 测试词条 %d", i), "", ""})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	dict := NewDictService(path)
+	if _, err := dict.Index(); !errors.Is(err, ErrDictIndexNotReady) {
+		t.Fatalf("Index() before BuildIndex error = %v, want ErrDictIndexNotReady", err)
+	}
+	if err := dict.BuildIndex(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if dict.index != nil {
+			dict.index.Close()
+		}
+	})
+	return dict
+}
+
+func TestDictEntries(t *testing.T) {
+	dict := newTestDict(t)
+	tests := []struct {
+		name string
+		word string
+		want string // 期望的 Translation，为空表示未找到
+	}{
+		{name: "首条", word: "apple", want: "n. 苹果"},
+		{name: "末条", word: "zebra", want: "n. 斑马"},
+		{name: "中间", word: "word1234", want: "n. 测试词条 1234"},
+		{name: "填充词首条", word: "word0000", want: "n. 测试词条 0"},
+		{name: "填充词末条", word: "word1999", want: "n. 测试词条 1999"},
+		{name: "大小写和空白", word: " Apple ", want: "n. 苹果"},
+		{name: "首条之前", word: "aa", want: ""},
+		{name: "末条之后", word: "zzz", want: ""},
+		{name: "两条之间", word: "word1234a", want: ""},
+		{name: "只有词形变化", word: "gone", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := dict.Entries(tt.word)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == "" {
+				if len(entries) != 0 {
+					t.Errorf("Entries(%q) = %v, want none", tt.word, entries)
+				}
+				return
+			}
+			if len(entries) != 1 || entries[0].Translation != tt.want {
+				t.Errorf("Entries(%q) = %v, want %q", tt.word, entries, tt.want)
+			}
+		})
+	}
+}
+
+func TestDictEntriesAll(t *testing.T) {
+	// 每个填充词都能找到，覆盖二分查找在各个位置的边界
+	dict := newTestDict(t)
+	for i := 0; i < dictTestFillers; i++ {
+		word := fmt.Sprintf("word%04d", i)
+		entries, err := dict.Entries(word)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 || entries[0].Word != word {
+			t.Fatalf("Entries(%q) = %v", word, entries)
+		}
+	}
+}
+
+func TestDictLemmas(t *testing.T) {
+	dict := newTestDict(t)
+	tests := []struct {
+		name string
+		word string
+		want []string
+	}{
+		{name: "词典中的过去式", word: "went", want: []string{"go"}},
+		{name: "词典中的过去分词", word: "gone", want: []string{"go"}},
+		{name: "词典中的复数", word: "Apples", want: []string{"apple"}},
+		{name: "按规则推测", word: "boxes", want: []string{"box"}},
+		{name: "原形没有原形", word: "go", want: nil},
+		{name: "无法推测", word: "zzz", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dict.Lemmas(tt.word)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lemmas(%q) = %q, want %q", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDictSuggestions(t *testing.T) {
+	dict := newTestDict(t)
+	tests := []struct {
+		name   string
+		prefix string
+		limit  int
+		want   []string
+	}{
+		{name: "前缀", prefix: "word12", limit: 3, want: []string{"word1200", "word1201", "word1202"}},
+		{name: "不含前缀本身", prefix: "word1999", limit: 3, want: nil},
+		{name: "末尾不足", prefix: "word199", limit: 20, want: []string{
+			"word1990", "word1991", "word1992", "word1993", "word1994",
+			"word1995", "word1996", "word1997", "word1998", "word1999",
+		}},
+		{name: "跳过词形变化记录", prefix: "g", limit: 5, want: []string{"go"}},
+		{name: "首条之前", prefix: "a", limit: 5, want: []string{"apple"}},
+		{name: "末条之后", prefix: "zz", limit: 5, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := dict.Suggestions(tt.prefix, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, entry := range entries {
+				got = append(got, entry.Word)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Suggestions(%q, %d) = %q, want %q", tt.prefix, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDictIndexLowerBound(t *testing.T) {
+	dict := newTestDict(t)
+	index, err := dict.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(dict.IndexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.size-index.start < 4*dictIndexSearchWindow {
+		t.Fatalf("index size = %d, too small to exercise binary search", index.size-index.start)
+	}
+
+	// firstAtLeast 顺序查找第一条键不小于 key 的行首偏移
+	firstAtLeast := func(key string) int64 {
+		offset := index.start
+		for _, line := range strings.SplitAfter(string(data[index.start:]), "\n") {
+			if line == "" || parseDictRecord(strings.TrimSuffix(line, "\n")).key >= key {
+				break
+			}
+			offset += int64(len(line))
+		}
+		return offset
+	}
+
+	for _, key := range []string{"", "aa", "apple", "go", "went", "word0000", "word1000", "word1999", "zebra", "zzz"} {
+		t.Run(key, func(t *testing.T) {
+			got, err := index.lowerBound(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := firstAtLeast(key)
+			if got > want || want-got > 2*dictIndexSearchWindow {
+				t.Errorf("lowerBound(%q) = %d, want within window before %d", key, got, want)
+			}
+			if got != index.start && data[got-1] != '\n' {
+				t.Errorf("lowerBound(%q) = %d, not at line start", key, got)
+			}
+		})
+	}
+}
+
+func TestDictIndexLineAfter(t *testing.T) {
+	dict := newTestDict(t)
+	index, err := dict.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(dict.IndexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := string(data[index.start : index.start+int64(strings.IndexByte(string(data[index.start:]), '\n'))])
+	second := index.start + int64(len(first)) + 1
+
+	tests := []struct {
+		name      string
+		offset    int64
+		wantStart int64
+	}{
+		{name: "行首", offset: index.start, wantStart: index.start},
+		{name: "行中", offset: index.start + 1, wantStart: second},
+		{name: "行尾换行符", offset: second - 1, wantStart: second},
+		{name: "文件末尾", offset: index.size, wantStart: index.size},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, line, err := index.lineAfter(tt.offset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if start != tt.wantStart {
+				t.Errorf("lineAfter(%d) start = %d, want %d", tt.offset, start, tt.wantStart)
+			}
+			if start < index.size {
+				want := strings.SplitN(string(data[start:]), "\n", 2)[0]
+				if line != want {
+					t.Errorf("lineAfter(%d) line = %q, want %q", tt.offset, line, want)
+				}
+			} else if line != "" {
+				t.Errorf("lineAfter(%d) line = %q, want empty", tt.offset, line)
+			}
+		})
+	}
+}
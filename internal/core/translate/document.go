@@ -0,0 +1,498 @@
+package translate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 文档格式
+const (
+	DocumentSRT  = "srt"
+	DocumentVTT  = "vtt"
+	DocumentPO   = "po" // 包括 .pot 模板
+	DocumentJSON = "json"
+)
+
+// formatPlaceholderPattern 文本中的格式化占位符，如 {name}、{{count}}、${user}、%s、%(name)s、%1$d
+var formatPlaceholderPattern = regexp.MustCompile(`\{\{[^{}]+\}\}|\$?\{[^{}\s]+\}|%(?:\(\w+\)|\d+\$)?[-+#0]*\d*(?:\.\d+)?[sdfiuxXoeEgGcqv@]|%%`)
+
+// DocumentEntry 文档中需要翻译的一条文本
+type DocumentEntry struct {
+	ID   string
+	Text string
+}
+
+// Document 可翻译的文档，除译文外按原样输出
+type Document interface {
+	// Entries 返回需要翻译的文本，ID 在文档内唯一
+	Entries() []DocumentEntry
+	// Render 输出译文替换原文后的文档，没有译文的条目保留原文
+	Render(translations map[string]string) []byte
+}
+
+// DetectDocumentFormat 根据扩展名识别文档格式，扩展名无法识别时根据内容判断
+func DetectDocumentFormat(path string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".srt":
+		return DocumentSRT
+	case ".vtt":
+		return DocumentVTT
+	case ".po", ".pot":
+		return DocumentPO
+	case ".json":
+		return DocumentJSON
+	}
+
+	text := strings.TrimSpace(strings.TrimPrefix(string(data), "\ufeff"))
+	switch {
+	case strings.HasPrefix(text, "WEBVTT"):
+		return DocumentVTT
+	case strings.HasPrefix(text, "{"):
+		return DocumentJSON
+	case strings.Contains(text, "-->"):
+		return DocumentSRT
+	case strings.Contains(text, "msgid "):
+		return DocumentPO
+	}
+	return ""
+}
+
+// ParseDocument 解析文档
+func ParseDocument(format string, data []byte) (Document, error) {
+	text := strings.ReplaceAll(strings.TrimPrefix(string(data), "\ufeff"), "\r\n", "\n")
+	switch format {
+	case DocumentSRT, DocumentVTT:
+		return parseSubtitle(text), nil
+	case DocumentPO:
+		return parsePO(text)
+	case DocumentJSON:
+		return parseJSONDocument(data)
+	}
+	return nil, fmt.Errorf("document error: unsupported format %q", format)
+}
+
+// MaskFormatPlaceholders 将格式化占位符替换为标记占位符，返回替换后的文本和被替换的内容
+func MaskFormatPlaceholders(text string) (string, []string) {
+	var values []string
+	masked := formatPlaceholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		values = append(values, match)
+		return markupPlaceholder(len(values) - 1)
+	})
+	return masked, values
+}
+
+// RestoreFormatPlaceholders 还原格式化占位符，译文中丢失的补在末尾
+func RestoreFormatPlaceholders(text string, values []string) string {
+	tags := make(map[int]string, len(values))
+	for i, value := range values {
+		tags[i] = value
+	}
+	return restoreMarkup(text, tags, true)
+}
+
+// subtitleDocument SRT/WebVTT 字幕，序号、时间轴、样式和注释原样保留
+type subtitleDocument struct {
+	blocks [][]string
+	cues   []subtitleCue
+}
+
+// subtitleCue 一条字幕的文字所在位置
+type subtitleCue struct {
+	block int
+	start int // 文字的第一行
+}
+
+// parseSubtitle 按空行拆分字幕块，含有时间轴(-->)的块中时间轴之后的行为字幕文字
+func parseSubtitle(text string) *subtitleDocument {
+	doc := &subtitleDocument{}
+	var block []string
+	flush := func() {
+		if len(block) > 0 {
+			doc.blocks = append(doc.blocks, block)
+			block = nil
+		}
+	}
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		block = append(block, line)
+	}
+	flush()
+
+	for i, lines := range doc.blocks {
+		if first := lines[0]; strings.HasPrefix(first, "NOTE") || first == "STYLE" || first == "REGION" {
+			continue
+		}
+		for j, line := range lines {
+			if strings.Contains(line, "-->") {
+				if j+1 < len(lines) {
+					doc.cues = append(doc.cues, subtitleCue{block: i, start: j + 1})
+				}
+				break
+			}
+		}
+	}
+	return doc
+}
+
+// Entries 每条字幕一项，多行字幕合并为一行
+func (d *subtitleDocument) Entries() []DocumentEntry {
+	entries := make([]DocumentEntry, 0, len(d.cues))
+	for i, cue := range d.cues {
+		entries = append(entries, DocumentEntry{
+			ID:   strconv.Itoa(i + 1),
+			Text: strings.Join(d.blocks[cue.block][cue.start:], " "),
+		})
+	}
+	return entries
+}
+
+// Render 输出字幕，字幕文字替换为译文
+func (d *subtitleDocument) Render(translations map[string]string) []byte {
+	blocks := make([][]string, len(d.blocks))
+	copy(blocks, d.blocks)
+	for i, cue := range d.cues {
+		translation, ok := translations[strconv.Itoa(i+1)]
+		if !ok {
+			continue
+		}
+		lines := append([]string{}, blocks[cue.block][:cue.start]...)
+		blocks[cue.block] = append(lines, strings.Split(translation, "\n")...)
+	}
+
+	var b strings.Builder
+	for i, lines := range blocks {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		for _, line := range lines {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	return []byte(b.String())
+}
+
+// poDocument gettext PO/POT 文件，只填写空的 msgstr，注释和已有译文原样保留
+type poDocument struct {
+	blocks  [][]string
+	entries []poEntry
+}
+
+// poEntry 需要翻译的条目
+type poEntry struct {
+	block      int
+	id         string
+	msgid      string
+	plural     string
+	strStart   int   // 第一行 msgstr 所在行
+	strEnd     int   // 最后一行 msgstr(含续行)之后的行
+	pluralKeys []int // msgstr[n] 的序号
+}
+
+// poLinePattern PO 的关键字行
+var poLinePattern = regexp.MustCompile(`^(msgctxt|msgid|msgid_plural|msgstr(?:\[(\d+)\])?)\s+(".*")\s*$`)
+
+// parsePO 按空行拆分条目并解析 msgctxt、msgid、msgid_plural 和 msgstr
+func parsePO(text string) (*poDocument, error) {
+	doc := &poDocument{}
+	var block []string
+	flush := func() {
+		if len(block) > 0 {
+			doc.blocks = append(doc.blocks, block)
+			block = nil
+		}
+	}
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		block = append(block, line)
+	}
+	flush()
+
+	for i, lines := range doc.blocks {
+		entry := poEntry{block: i, strStart: -1}
+		var ctxt string
+		var translated []string
+		field := ""
+		for j, line := range lines {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+
+			value := trimmed
+			if match := poLinePattern.FindStringSubmatch(trimmed); match != nil {
+				field, value = match[1], match[3]
+				if strings.HasPrefix(field, "msgstr") {
+					if entry.strStart < 0 {
+						entry.strStart = j
+					}
+					translated = append(translated, "")
+					if match[2] != "" {
+						n, _ := strconv.Atoi(match[2])
+						entry.pluralKeys = append(entry.pluralKeys, n)
+					}
+				}
+			} else if !strings.HasPrefix(trimmed, `"`) {
+				return nil, fmt.Errorf("document error: invalid po line %q", line)
+			}
+
+			s, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("document error: invalid po string %s", value)
+			}
+			switch {
+			case field == "msgctxt":
+				ctxt += s
+			case field == "msgid":
+				entry.msgid += s
+			case field == "msgid_plural":
+				entry.plural += s
+			case strings.HasPrefix(field, "msgstr"):
+				translated[len(translated)-1] += s
+				entry.strEnd = j + 1
+			}
+		}
+
+		// 跳过头部、已翻译的条目和没有 msgstr 的块(如只有注释)
+		if entry.msgid == "" || entry.strStart < 0 || strings.Join(translated, "") != "" {
+			continue
+		}
+		entry.id = entry.msgid
+		if ctxt != "" {
+			entry.id = ctxt + "\x04" + entry.msgid
+		}
+		doc.entries = append(doc.entries, entry)
+	}
+	return doc, nil
+}
+
+// poPluralSuffix 复数形式条目 ID 的后缀
+const poPluralSuffix = "\x00plural"
+
+// Entries 每个未翻译的 msgid 一项，有复数形式时 msgid_plural 单独一项
+func (d *poDocument) Entries() []DocumentEntry {
+	var entries []DocumentEntry
+	for _, entry := range d.entries {
+		entries = append(entries, DocumentEntry{ID: entry.id, Text: entry.msgid})
+		if entry.plural != "" {
+			entries = append(entries, DocumentEntry{ID: entry.id + poPluralSuffix, Text: entry.plural})
+		}
+	}
+	return entries
+}
+
+// Render 输出 PO 文件，未翻译条目的 msgstr 替换为译文，复数形式的 msgstr[0] 使用单数译文
+func (d *poDocument) Render(translations map[string]string) []byte {
+	blocks := make([][]string, len(d.blocks))
+	copy(blocks, d.blocks)
+	for _, entry := range d.entries {
+		singular, ok := translations[entry.id]
+		if !ok {
+			continue
+		}
+		var msgstr []string
+		if len(entry.pluralKeys) == 0 {
+			msgstr = []string{"msgstr " + poQuote(singular)}
+		} else {
+			plural, ok := translations[entry.id+poPluralSuffix]
+			if !ok {
+				plural = singular
+			}
+			for _, n := range entry.pluralKeys {
+				value := plural
+				if n == 0 {
+					value = singular
+				}
+				msgstr = append(msgstr, fmt.Sprintf("msgstr[%d] %s", n, poQuote(value)))
+			}
+		}
+
+		lines := blocks[entry.block]
+		replaced := append([]string{}, lines[:entry.strStart]...)
+		replaced = append(replaced, msgstr...)
+		blocks[entry.block] = append(replaced, lines[entry.strEnd:]...)
+	}
+
+	var b strings.Builder
+	for i, lines := range blocks {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		for _, line := range lines {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	return []byte(b.String())
+}
+
+// poQuote 按 PO 格式转义字符串，非 ASCII 字符原样保留
+func poQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`).Replace(s) + `"`
+}
+
+// jsonNode 保留键顺序的 JSON 值
+type jsonNode struct {
+	keys   []string    // 对象的键
+	values []*jsonNode // 对象或数组的值
+	array  bool
+	object bool
+	str    *string         // 字符串
+	raw    json.RawMessage // 数字、布尔值和 null
+}
+
+// jsonDocument JSON 国际化资源，翻译所有字符串值，键和其他类型的值原样保留
+type jsonDocument struct {
+	root *jsonNode
+}
+
+// parseJSONDocument 按原有键顺序解析 JSON
+func parseJSONDocument(data []byte) (*jsonDocument, error) {
+	decoder := json.NewDecoder(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	decoder.UseNumber()
+	root, err := decodeJSONNode(decoder)
+	if err != nil {
+		return nil, fmt.Errorf("document error: %w", err)
+	}
+	return &jsonDocument{root: root}, nil
+}
+
+// decodeJSONNode 读取一个 JSON 值
+func decodeJSONNode(decoder *json.Decoder) (*jsonNode, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		node := &jsonNode{object: t == '{', array: t == '['}
+		for decoder.More() {
+			if node.object {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.keys = append(node.keys, key.(string))
+			}
+			value, err := decodeJSONNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node.values = append(node.values, value)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return &jsonNode{str: &t}, nil
+	default:
+		raw, err := json.Marshal(t)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{raw: raw}, nil
+	}
+}
+
+// Entries 每个字符串值一项，ID 为以 . 连接的键路径，数组元素使用序号，键中的 . 和 \ 前加 \ 转义
+func (d *jsonDocument) Entries() []DocumentEntry {
+	var entries []DocumentEntry
+	d.root.walk("", func(path string, node *jsonNode) {
+		entries = append(entries, DocumentEntry{ID: path, Text: *node.str})
+	})
+	return entries
+}
+
+// Render 输出缩进为两个空格的 JSON，字符串值替换为译文
+func (d *jsonDocument) Render(translations map[string]string) []byte {
+	var b bytes.Buffer
+	d.root.render(&b, "", "", translations)
+	b.WriteString("\n")
+	return b.Bytes()
+}
+
+// walk 按顺序遍历字符串值
+func (n *jsonNode) walk(path string, fn func(string, *jsonNode)) {
+	switch {
+	case n.str != nil:
+		fn(path, n)
+	case n.object || n.array:
+		for i, value := range n.values {
+			value.walk(n.childPath(path, i), fn)
+		}
+	}
+}
+
+// jsonKeyEscaper 转义键中的 . 和 \，避免 {"a.b": …} 与 {"a": {"b": …}} 的路径相同
+var jsonKeyEscaper = strings.NewReplacer(`\`, `\\`, ".", `\.`)
+
+// childPath 返回第 i 个子节点的路径
+func (n *jsonNode) childPath(path string, i int) string {
+	key := strconv.Itoa(i)
+	if n.object {
+		key = jsonKeyEscaper.Replace(n.keys[i])
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// render 输出节点
+func (n *jsonNode) render(b *bytes.Buffer, path, indent string, translations map[string]string) {
+	switch {
+	case n.str != nil:
+		value := *n.str
+		if translation, ok := translations[path]; ok {
+			value = translation
+		}
+		b.Write(jsonString(value))
+	case n.object || n.array:
+		open, end := "[", "]"
+		if n.object {
+			open, end = "{", "}"
+		}
+		if len(n.values) == 0 {
+			b.WriteString(open + end)
+			return
+		}
+		b.WriteString(open + "\n")
+		for i, value := range n.values {
+			b.WriteString(indent + "  ")
+			if n.object {
+				b.Write(jsonString(n.keys[i]))
+				b.WriteString(": ")
+			}
+			value.render(b, n.childPath(path, i), indent+"  ", translations)
+			if i < len(n.values)-1 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + end)
+	default:
+		b.Write(n.raw)
+	}
+}
+
+// jsonString 编码 JSON 字符串，不转义 HTML 字符
+func jsonString(s string) []byte {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n"))
+}
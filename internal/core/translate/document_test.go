@@ -0,0 +1,217 @@
+package translate
+
+import (
+	"strings"
+	"testing"
+)
+
+// documentTest 文档解析和输出的测试用例
+type documentTest struct {
+	name         string
+	format       string
+	input        string
+	entries      []DocumentEntry
+	translations map[string]string
+	want         string
+}
+
+var documentTests = []documentTest{
+	{
+		name:   "SRT",
+		format: DocumentSRT,
+		input:  "1\n00:00:01,000 --> 00:00:02,000\nHello\n\n2\n00:00:03,000 --> 00:00:04,000\nTwo\nlines\n",
+		entries: []DocumentEntry{
+			{ID: "1", Text: "Hello"},
+			{ID: "2", Text: "Two lines"},
+		},
+		translations: map[string]string{"1": "你好", "2": "两行\n文字"},
+		want:         "1\n00:00:01,000 --> 00:00:02,000\n你好\n\n2\n00:00:03,000 --> 00:00:04,000\n两行\n文字\n",
+	},
+	{
+		name:   "WebVTT",
+		format: DocumentVTT,
+		input:  "WEBVTT\n\nSTYLE\n::cue { color: red }\n\nNOTE comment --> kept\n\nintro\n00:01.000 --> 00:02.000 align:start\n<v Bob>Hi</v>\n",
+		entries: []DocumentEntry{
+			{ID: "1", Text: "<v Bob>Hi</v>"},
+		},
+		translations: map[string]string{"1": "<v Bob>嗨</v>"},
+		want:         "WEBVTT\n\nSTYLE\n::cue { color: red }\n\nNOTE comment --> kept\n\nintro\n00:01.000 --> 00:02.000 align:start\n<v Bob>嗨</v>\n",
+	},
+	{
+		name:   "PO",
+		format: DocumentPO,
+		input: strings.Join([]string{
+			`msgid ""`,
+			`msgstr ""`,
+			`"Content-Type: text/plain; charset=UTF-8\n"`,
+			``,
+			`#: main.c:1`,
+			`msgctxt "menu"`,
+			`msgid "Open"`,
+			`msgstr ""`,
+			``,
+			`msgid "Done"`,
+			`msgstr "完成"`,
+			``,
+			`msgid ""`,
+			`"%d file "`,
+			`"deleted"`,
+			`msgid_plural "%d files deleted"`,
+			`msgstr[0] ""`,
+			`msgstr[1] ""`,
+		}, "\n") + "\n",
+		entries: []DocumentEntry{
+			{ID: "menu\x04Open", Text: "Open"},
+			{ID: "%d file deleted", Text: "%d file deleted"},
+			{ID: "%d file deleted" + poPluralSuffix, Text: "%d files deleted"},
+		},
+		translations: map[string]string{
+			"menu\x04Open":                     "打开",
+			"%d file deleted":                  "已删除 %d 个文件",
+			"%d file deleted" + poPluralSuffix: "已删除 %d 个文件\"",
+		},
+		want: strings.Join([]string{
+			`msgid ""`,
+			`msgstr ""`,
+			`"Content-Type: text/plain; charset=UTF-8\n"`,
+			``,
+			`#: main.c:1`,
+			`msgctxt "menu"`,
+			`msgid "Open"`,
+			`msgstr "打开"`,
+			``,
+			`msgid "Done"`,
+			`msgstr "完成"`,
+			``,
+			`msgid ""`,
+			`"%d file "`,
+			`"deleted"`,
+			`msgid_plural "%d files deleted"`,
+			`msgstr[0] "已删除 %d 个文件"`,
+			`msgstr[1] "已删除 %d 个文件\""`,
+		}, "\n") + "\n",
+	},
+	{
+		name:   "JSON",
+		format: DocumentJSON,
+		input:  "{\n  \"title\": \"Hello <b>\",\n  \"count\": 1.50,\n  \"empty\": {},\n  \"menu\": {\n    \"items\": [\n      \"Open\",\n      true,\n      null\n    ]\n  }\n}\n",
+		entries: []DocumentEntry{
+			{ID: "title", Text: "Hello <b>"},
+			{ID: "menu.items.0", Text: "Open"},
+		},
+		translations: map[string]string{"title": "你好 <b>", "menu.items.0": "打开"},
+		want:         "{\n  \"title\": \"你好 <b>\",\n  \"count\": 1.50,\n  \"empty\": {},\n  \"menu\": {\n    \"items\": [\n      \"打开\",\n      true,\n      null\n    ]\n  }\n}\n",
+	},
+	{
+		// 键中的 . 转义，与嵌套对象的路径不同
+		name:   "JSON键含有点",
+		format: DocumentJSON,
+		input:  "{\n  \"a.b\": \"x\",\n  \"a\": {\n    \"b\": \"y\"\n  },\n  \"c\\\\\": \"z\"\n}\n",
+		entries: []DocumentEntry{
+			{ID: `a\.b`, Text: "x"},
+			{ID: "a.b", Text: "y"},
+			{ID: `c\\`, Text: "z"},
+		},
+		translations: map[string]string{`a\.b`: "叉", "a.b": "歪", `c\\`: "兹"},
+		want:         "{\n  \"a.b\": \"叉\",\n  \"a\": {\n    \"b\": \"歪\"\n  },\n  \"c\\\\\": \"兹\"\n}\n",
+	},
+}
+
+func TestDocumentRoundTrip(t *testing.T) {
+	for _, tt := range documentTests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseDocument(tt.format, []byte(tt.input))
+			if err != nil {
+				t.Fatalf("ParseDocument() error = %v", err)
+			}
+
+			entries := doc.Entries()
+			if len(entries) != len(tt.entries) {
+				t.Fatalf("Entries() = %q, want %q", entries, tt.entries)
+			}
+			for i := range entries {
+				if entries[i] != tt.entries[i] {
+					t.Errorf("Entries()[%d] = %q, want %q", i, entries[i], tt.entries[i])
+				}
+			}
+
+			// 没有译文时原样输出
+			if got := string(doc.Render(nil)); got != tt.input {
+				t.Errorf("Render(nil) =\n%s\nwant\n%s", got, tt.input)
+			}
+			if got := string(doc.Render(tt.translations)); got != tt.want {
+				t.Errorf("Render() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocumentCRLFAndBOM(t *testing.T) {
+	doc, err := ParseDocument(DocumentSRT, []byte("\ufeff1\r\n00:00:01,000 --> 00:00:02,000\r\nHello\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "1\n00:00:01,000 --> 00:00:02,000\nHello\n"
+	if got := string(doc.Render(nil)); got != want {
+		t.Errorf("Render(nil) = %q, want %q", got, want)
+	}
+}
+
+func TestParsePOErrors(t *testing.T) {
+	for _, input := range []string{
+		"msgid \"a\"\nmsgstr \"\"\ngarbage\n",
+		"msgid \"unterminated\nmsgstr \"\"\n",
+	} {
+		if _, err := ParseDocument(DocumentPO, []byte(input)); err == nil {
+			t.Errorf("ParseDocument(%q) error = nil, want error", input)
+		}
+	}
+}
+
+func TestDetectDocumentFormat(t *testing.T) {
+	tests := []struct {
+		path string
+		data string
+		want string
+	}{
+		{path: "a.srt", want: DocumentSRT},
+		{path: "a.VTT", want: DocumentVTT},
+		{path: "a.pot", want: DocumentPO},
+		{path: "zh.json", want: DocumentJSON},
+		{path: "-", data: "WEBVTT\n", want: DocumentVTT},
+		{path: "-", data: " {\"a\": \"b\"}", want: DocumentJSON},
+		{path: "-", data: "1\n00:00:01,000 --> 00:00:02,000\nhi", want: DocumentSRT},
+		{path: "-", data: "msgid \"a\"\nmsgstr \"\"", want: DocumentPO},
+		{path: "a.txt", data: "plain", want: ""},
+	}
+	for _, tt := range tests {
+		if got := DetectDocumentFormat(tt.path, []byte(tt.data)); got != tt.want {
+			t.Errorf("DetectDocumentFormat(%q, %q) = %q, want %q", tt.path, tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestFormatPlaceholders(t *testing.T) {
+	tests := []struct {
+		text        string
+		masked      string
+		translation string
+		want        string
+	}{
+		{text: "Hello {name}", masked: "Hello ⟨0⟩", translation: "你好 ⟨0⟩", want: "你好 {name}"},
+		{text: "%d of {{total}} by ${user}", masked: "⟨0⟩ of ⟨1⟩ by ⟨2⟩", translation: "⟨2⟩ 的 ⟨1⟩ 中的 ⟨0⟩", want: "${user} 的 {{total}} 中的 %d"},
+		{text: "%(count)s%% %1$s", masked: "⟨0⟩⟨1⟩ ⟨2⟩", translation: "⟨0⟩⟨1⟩", want: "%(count)s%%%1$s"},
+		{text: "no placeholders", masked: "no placeholders", translation: "没有占位符", want: "没有占位符"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			masked, values := MaskFormatPlaceholders(tt.text)
+			if masked != tt.masked {
+				t.Errorf("MaskFormatPlaceholders(%q) = %q, want %q", tt.text, masked, tt.masked)
+			}
+			if got := RestoreFormatPlaceholders(tt.translation, values); got != tt.want {
+				t.Errorf("RestoreFormatPlaceholders(%q) = %q, want %q", tt.translation, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// ErrorKind 翻译错误类型
+type ErrorKind int
+
+const (
+	ErrorUnknown             ErrorKind = iota // 其他错误
+	ErrorAuth                                 // 认证失败：密钥错误、签名错误、IP白名单等
+	ErrorQuota                                // 额度不足或账户欠费
+	ErrorRateLimited                          // 请求频率受限
+	ErrorUnsupportedLanguage                  // 不支持的语言或语言方向
+	ErrorNetwork                              // 网络错误或超时
+	ErrorBadResponse                          // HTTP状态异常或响应无法解析
+	ErrorPrivacy                              // 查询包含敏感信息，未发送到在线服务
+)
+
+// errorKindNames 错误类型的说明
+var errorKindNames = map[ErrorKind]string{
+	ErrorUnknown:             "翻译失败",
+	ErrorAuth:                "认证失败",
+	ErrorQuota:               "额度不足",
+	ErrorRateLimited:         "请求频率受限",
+	ErrorUnsupportedLanguage: "不支持的语言",
+	ErrorNetwork:             "网络错误",
+	ErrorBadResponse:         "响应异常",
+	ErrorPrivacy:             "包含敏感信息",
+}
+
+// String 返回错误类型的说明
+func (k ErrorKind) String() string {
+	return errorKindNames[k]
+}
+
+// Error 翻译服务错误
+type Error struct {
+	Provider string    `json:"provider"`
+	Kind     ErrorKind `json:"kind"`
+	Code     string    `json:"code,omitempty"` // 服务返回的错误码或HTTP状态码
+	Message  string    `json:"message"`
+	Err      error     `json:"-"` // 原始错误
+}
+
+// NewError 创建翻译服务错误
+func NewError(provider string, kind ErrorKind, code, message string) *Error {
+	return &Error{
+		Provider: provider,
+		Kind:     kind,
+		Code:     code,
+		Message:  message,
+	}
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	msg := e.Message
+	if e.Code != "" {
+		msg += " (" + e.Code + ")"
+	}
+	if e.Provider != "" {
+		msg = e.Provider + ": " + msg
+	}
+	return msg
+}
+
+// Unwrap 返回原始错误
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WrapError 将错误转换为 *Error 并附加服务提供方名称
+// 网络错误、超时和HTTP状态错误按类型归类，已是 *Error 的保留原有类型
+func WrapError(provider string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var translateErr *Error
+	if errors.As(err, &translateErr) {
+		if translateErr.Provider == "" {
+			translateErr.Provider = provider
+		}
+		return err
+	}
+
+	wrapped := &Error{Provider: provider, Kind: ErrorUnknown, Message: err.Error(), Err: err}
+	var statusErr *HTTPStatusError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		wrapped.Kind = ErrorNetwork
+		wrapped.Message = "请求超时"
+	case errors.As(err, &statusErr):
+		wrapped.Kind = ErrorBadResponse
+		wrapped.Code = strconv.Itoa(statusErr.StatusCode)
+		wrapped.Message = "服务端错误"
+	case errors.As(err, &netErr):
+		wrapped.Kind = ErrorNetwork
+	}
+	return wrapped
+}
+
+// Errors 展开错误(包括 errors.Join 合并的错误)中的所有翻译服务错误
+func Errors(err error) []*Error {
+	if err == nil {
+		return nil
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []*Error
+		for _, e := range joined.Unwrap() {
+			errs = append(errs, Errors(e)...)
+		}
+		return errs
+	}
+
+	var translateErr *Error
+	if errors.As(err, &translateErr) {
+		return []*Error{translateErr}
+	}
+	return []*Error{{Kind: ErrorUnknown, Message: err.Error(), Err: err}}
+}
+
+// KindOf 返回错误的类型
+func KindOf(err error) ErrorKind {
+	var translateErr *Error
+	if errors.As(err, &translateErr) {
+		return translateErr.Kind
+	}
+	return ErrorUnknown
+}
+
+// decodeJSON 检查HTTP状态并解析JSON响应，状态异常或响应不是JSON时返回 *Error
+func decodeJSON(status int, body []byte, out interface{}) error {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return NewError("", ErrorAuth, strconv.Itoa(status), "认证失败，请检查密钥配置")
+	case status == http.StatusTooManyRequests:
+		return NewError("", ErrorRateLimited, strconv.Itoa(status), "请求过于频繁，请稍后重试")
+	case status >= http.StatusBadRequest:
+		return NewError("", ErrorBadResponse, strconv.Itoa(status), "HTTP状态异常: "+responseSnippet(body))
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return &Error{
+			Kind:    ErrorBadResponse,
+			Message: "响应不是有效的JSON: " + responseSnippet(body),
+			Err:     err,
+		}
+	}
+	return nil
+}
+
+// responseSnippet 截取响应内容的开头用于错误提示
+func responseSnippet(body []byte) string {
+	const maxLen = 80
+	runes := []rune(string(body))
+	if len(runes) > maxLen {
+		return fmt.Sprintf("%s...", string(runes[:maxLen]))
+	}
+	return string(runes)
+}
+
+// providerService 为服务返回的错误附加服务提供方名称
+type providerService struct {
+	provider string
+	service  Service
+}
+
+// Local 服务是否为本地服务
+func (s *providerService) Local() bool {
+	return IsLocal(s.service)
+}
+
+// Translate 调用服务翻译，错误统一转换为 *Error
+func (s *providerService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
+	results, err := s.service.Translate(ctx, query)
+	if err != nil {
+		return nil, WrapError(s.provider, err)
+	}
+	return results, nil
+}
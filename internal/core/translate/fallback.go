@@ -0,0 +1,39 @@
+package translate
+
+import (
+	"context"
+	"errors"
+)
+
+// FallbackService 按顺序尝试多个翻译服务，返回第一个成功的结果
+type FallbackService struct {
+	Services []Service
+}
+
+// NewFallbackService 创建按顺序回退的翻译服务
+func NewFallbackService(services ...Service) *FallbackService {
+	return &FallbackService{
+		Services: services,
+	}
+}
+
+// Translate 依次调用各服务，直到有服务返回结果
+func (s *FallbackService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
+	var errs []error
+	for _, service := range s.Services {
+		if ctx.Err() != nil {
+			break
+		}
+		results, err := service.Translate(ctx, query)
+		if err == nil && len(results) > 0 {
+			return results, nil
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil, errors.New("fallback translation error: no result")
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package translate
+
+// lockFile 非 unix 平台不加文件锁，只有进程内的互斥
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package translate
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile 打开 path 并加排他锁，其他进程加锁时等待，返回解锁函数
+func lockFile(path string) (func(), error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return func() {
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}, nil
+}
@@ -0,0 +1,342 @@
+package translate
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GlossaryConfig 术语表配置
+type GlossaryConfig struct {
+	File      string            `yaml:"file,omitempty"`      // 术语表文件 CSV(原文,译文) 或 YAML，相对路径相对于配置文件所在目录
+	Terms     map[string]string `yaml:"terms,omitempty"`     // 原文 → 译文
+	Protected []string          `yaml:"protected,omitempty"` // 不翻译的词，如产品名、代码标识符
+}
+
+// GlossaryTerm 术语，原文翻译为指定译文
+type GlossaryTerm struct {
+	Source string
+	Target string
+}
+
+// Glossary 术语表
+type Glossary struct {
+	Terms     []GlossaryTerm
+	Protected []string
+}
+
+// LoadGlossary 根据配置加载术语表，文件中的术语在前，配置中的同名术语覆盖文件中的
+func LoadGlossary(config GlossaryConfig, baseDir string) (*Glossary, error) {
+	glossary := &Glossary{}
+	if config.File != "" {
+		path := config.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		loaded, err := LoadGlossaryFile(path)
+		if err != nil {
+			return nil, err
+		}
+		glossary = loaded
+	}
+
+	sources := make([]string, 0, len(config.Terms))
+	for source := range config.Terms {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		glossary.Add(source, config.Terms[source])
+	}
+	glossary.Protected = append(glossary.Protected, config.Protected...)
+	return glossary, nil
+}
+
+// LoadGlossaryFile 加载术语表文件，按扩展名识别 CSV 或 YAML
+// CSV 每行 原文,译文，只有一列或译文为空时作为不翻译的词，# 开头的行为注释
+// YAML 格式与配置中的 glossary 相同(terms 和 protected)
+func LoadGlossaryFile(path string) (*Glossary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var config GlossaryConfig
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("glossary error: %s: %w", path, err)
+		}
+		config.File = ""
+		return LoadGlossary(config, "")
+	default:
+		glossary, err := parseGlossaryCSV(strings.NewReader(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("glossary error: %s: %w", path, err)
+		}
+		return glossary, nil
+	}
+}
+
+// parseGlossaryCSV 解析 CSV 格式的术语表
+func parseGlossaryCSV(r io.Reader) (*Glossary, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	glossary := &Glossary{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		source := strings.TrimSpace(record[0])
+		if source == "" {
+			continue
+		}
+		target := ""
+		if len(record) > 1 {
+			target = strings.TrimSpace(record[1])
+		}
+		if target == "" {
+			glossary.Protected = append(glossary.Protected, source)
+			continue
+		}
+		glossary.Add(source, target)
+	}
+	return glossary, nil
+}
+
+// Add 添加术语，已存在的原文覆盖其译文
+func (g *Glossary) Add(source, target string) {
+	for i, term := range g.Terms {
+		if term.Source == source {
+			g.Terms[i].Target = target
+			return
+		}
+	}
+	g.Terms = append(g.Terms, GlossaryTerm{Source: source, Target: target})
+}
+
+// Empty 术语表是否为空
+func (g *Glossary) Empty() bool {
+	return g == nil || (len(g.Terms) == 0 && len(g.Protected) == 0)
+}
+
+// Key 返回术语表内容的标识，用于区分缓存
+func (g *Glossary) Key() string {
+	var b strings.Builder
+	for _, term := range g.Terms {
+		b.WriteString(term.Source + "\x00" + term.Target + "\x00")
+	}
+	for _, token := range g.Protected {
+		b.WriteString(token + "\x01")
+	}
+	return Md5(b.String())
+}
+
+// placeholderPattern 占位符，兼容服务在括号内插入空格的情况
+// 不匹配半角方括号，避免把查询中原有的 [0]、arr[1] 等当作占位符
+var placeholderPattern = regexp.MustCompile(`⟦\s*(\d+)\s*⟧`)
+
+// placeholder 返回第 i 个占位符
+func placeholder(i int) string {
+	return "⟦" + strconv.Itoa(i) + "⟧"
+}
+
+// maskedTerm 被占位符替换的文本
+type maskedTerm struct {
+	original    string // 查询中的原文
+	replacement string // 译文中的替换内容
+}
+
+// Masked 替换为占位符后的查询
+type Masked struct {
+	Query string
+	terms []maskedTerm
+}
+
+// Mask 将查询中的不翻译的词和术语替换为占位符
+// includeTerms 为 false 时只替换不翻译的词，术语交给支持原生术语表的服务处理
+// 整个查询就是一个术语或不翻译的词时不替换，以便查询该词本身的翻译
+func (g *Glossary) Mask(query string, includeTerms bool) *Masked {
+	masked := &Masked{Query: query}
+	if g.Empty() {
+		return masked
+	}
+
+	type candidate struct {
+		pattern     string
+		replacement string
+		ignoreCase  bool
+	}
+	var candidates []candidate
+	for _, token := range g.Protected {
+		candidates = append(candidates, candidate{pattern: token, replacement: token})
+	}
+	if includeTerms {
+		for _, term := range g.Terms {
+			candidates = append(candidates, candidate{pattern: term.Source, replacement: term.Target, ignoreCase: true})
+		}
+	}
+
+	trimmed := strings.TrimSpace(query)
+	for _, c := range candidates {
+		if strings.EqualFold(trimmed, c.pattern) {
+			return masked
+		}
+	}
+
+	// 长的优先，避免短词替换掉长词的一部分
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return len(candidates[i].pattern) > len(candidates[j].pattern)
+	})
+	for _, c := range candidates {
+		re := termPattern(c.pattern, c.ignoreCase)
+		if re == nil {
+			continue
+		}
+		masked.Query = re.ReplaceAllStringFunc(masked.Query, func(match string) string {
+			masked.terms = append(masked.terms, maskedTerm{original: match, replacement: c.replacement})
+			return placeholder(len(masked.terms) - 1)
+		})
+	}
+	return masked
+}
+
+// termPattern 返回匹配术语的正则，以字母数字开头或结尾的术语要求词边界
+func termPattern(term string, ignoreCase bool) *regexp.Regexp {
+	if strings.TrimSpace(term) == "" {
+		return nil
+	}
+	pattern := regexp.QuoteMeta(term)
+	if first, _ := utf8.DecodeRuneInString(term); isASCIIWordChar(first) {
+		pattern = `\b` + pattern
+	}
+	if last, _ := utf8.DecodeLastRuneInString(term); isASCIIWordChar(last) {
+		pattern += `\b`
+	}
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.MustCompile(pattern)
+}
+
+// isASCIIWordChar 是否为英文字母、数字或下划线
+func isASCIIWordChar(r rune) bool {
+	return r <= unicode.MaxASCII && (r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// Restore 将译文中的占位符还原为术语的译文或不翻译的原词
+func (m *Masked) Restore(text string) string {
+	return m.restore(text, func(term maskedTerm) string { return term.replacement })
+}
+
+// RestoreQuery 将文本中的占位符还原为查询原文，用于副标题等包含查询的文本
+func (m *Masked) RestoreQuery(text string) string {
+	return m.restore(text, func(term maskedTerm) string { return term.original })
+}
+
+// restore 替换占位符，序号无效的占位符保持原样
+func (m *Masked) restore(text string, value func(maskedTerm) string) string {
+	if len(m.terms) == 0 {
+		return text
+	}
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		i, err := strconv.Atoi(placeholderPattern.FindStringSubmatch(match)[1])
+		if err != nil || i >= len(m.terms) {
+			return match
+		}
+		return value(m.terms[i])
+	})
+}
+
+// GlossarySupporter 支持原生术语表的服务(如 DeepL 术语表、大模型提示词)
+// 支持时术语通过 context 传给服务，只有不翻译的词替换为占位符
+type GlossarySupporter interface {
+	SupportsGlossary() bool
+}
+
+// glossaryContextKey context 中术语的键
+type glossaryContextKey struct{}
+
+// WithGlossaryTerms 返回携带术语的 context
+func WithGlossaryTerms(ctx context.Context, terms []GlossaryTerm) context.Context {
+	return context.WithValue(ctx, glossaryContextKey{}, terms)
+}
+
+// GlossaryTermsFromContext 返回 context 中的术语
+func GlossaryTermsFromContext(ctx context.Context) []GlossaryTerm {
+	terms, _ := ctx.Value(glossaryContextKey{}).([]GlossaryTerm)
+	return terms
+}
+
+// GlossaryService 使用术语表的翻译服务
+type GlossaryService struct {
+	Service  Service
+	Glossary *Glossary
+}
+
+// NewGlossaryService 为翻译服务添加术语表
+func NewGlossaryService(service Service, glossary *Glossary) *GlossaryService {
+	return &GlossaryService{
+		Service:  service,
+		Glossary: glossary,
+	}
+}
+
+// Translate 替换查询中的术语和不翻译的词后翻译，并在结果中还原
+func (s *GlossaryService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
+	native := false
+	if supporter, ok := s.Service.(GlossarySupporter); ok && len(s.Glossary.Terms) > 0 {
+		native = supporter.SupportsGlossary()
+	}
+	if native {
+		ctx = WithGlossaryTerms(ctx, s.Glossary.Terms)
+	}
+
+	masked := s.Glossary.Mask(query, !native)
+	results, err := s.Service.Translate(ctx, masked.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		results[i].Title = masked.Restore(results[i].Title)
+		results[i].Value = masked.Restore(results[i].Value)
+		results[i].Subtitle = masked.RestoreQuery(results[i].Subtitle)
+		if speech := results[i].Speech; speech != nil {
+			restored := *speech
+			if speech.Text == masked.Query {
+				restored.Text = query
+			} else {
+				restored.Text = masked.Restore(speech.Text)
+			}
+			results[i].Speech = &restored
+		}
+	}
+	return results, nil
+}
+
+// SupportsGlossary 服务是否支持原生术语表
+func (s *providerService) SupportsGlossary() bool {
+	supporter, ok := s.service.(GlossarySupporter)
+	return ok && supporter.SupportsGlossary()
+}
@@ -0,0 +1,76 @@
+package translate
+
+import "testing"
+
+func TestGlossaryMaskRestore(t *testing.T) {
+	glossary := &Glossary{
+		Terms:     []GlossaryTerm{{Source: "cache", Target: "缓存"}},
+		Protected: []string{"Alfred"},
+	}
+	tests := []struct {
+		name        string
+		query       string
+		masked      string
+		translation string // 服务返回的译文
+		want        string
+		wantQuery   string
+	}{
+		{
+			name:        "替换术语和不翻译的词",
+			query:       "Alfred cache",
+			masked:      "⟦0⟧ ⟦1⟧",
+			translation: "⟦0⟧ 的 ⟦1⟧",
+			want:        "Alfred 的 缓存",
+			wantQuery:   "Alfred 的 cache",
+		},
+		{
+			name:        "占位符内插入空格",
+			query:       "clear the cache",
+			masked:      "clear the ⟦0⟧",
+			translation: "清除 ⟦ 0 ⟧",
+			want:        "清除 缓存",
+			wantQuery:   "清除 cache",
+		},
+		{
+			name:        "原有的方括号数字保持原样",
+			query:       "cache[0] and arr[1]",
+			masked:      "⟦0⟧[0] and arr[1]",
+			translation: "⟦0⟧[0] 和 arr[1]",
+			want:        "缓存[0] 和 arr[1]",
+			wantQuery:   "cache[0] 和 arr[1]",
+		},
+		{
+			name:        "序号超出范围的占位符保持原样",
+			query:       "cache",
+			masked:      "cache",
+			translation: "⟦3⟧",
+			want:        "⟦3⟧",
+			wantQuery:   "⟦3⟧",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			masked := glossary.Mask(tt.query, true)
+			if masked.Query != tt.masked {
+				t.Errorf("Mask(%q) = %q, want %q", tt.query, masked.Query, tt.masked)
+			}
+			if got := masked.Restore(tt.translation); got != tt.want {
+				t.Errorf("Restore(%q) = %q, want %q", tt.translation, got, tt.want)
+			}
+			if got := masked.RestoreQuery(tt.translation); got != tt.wantQuery {
+				t.Errorf("RestoreQuery(%q) = %q, want %q", tt.translation, got, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestGlossaryMaskProtectedOnly(t *testing.T) {
+	glossary := &Glossary{
+		Terms:     []GlossaryTerm{{Source: "cache", Target: "缓存"}},
+		Protected: []string{"Alfred"},
+	}
+	// 支持原生术语表的服务只替换不翻译的词
+	if got := glossary.Mask("Alfred cache", false).Query; got != "⟦0⟧ cache" {
+		t.Errorf("Mask() = %q, want %q", got, "⟦0⟧ cache")
+	}
+}
@@ -0,0 +1,318 @@
+package translate
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// defaultHistoryMaxEntries 默认最多保存的历史记录条数，收藏的记录不计入也不会被淘汰
+const defaultHistoryMaxEntries = 500
+
+// HistoryConfig 翻译历史配置
+type HistoryConfig struct {
+	Disabled   bool   `yaml:"disabled,omitempty"`
+	MaxEntries int    `yaml:"max_entries,omitempty"` // 最多保存的未收藏记录条数
+	Keyword    string `yaml:"keyword,omitempty"`     // 以该关键字开头的查询搜索历史，默认 h:
+}
+
+// HistoryEntry 一条翻译历史
+type HistoryEntry struct {
+	ID       string    `json:"id"`
+	Query    string    `json:"query"`
+	Result   string    `json:"result"`
+	Provider string    `json:"provider,omitempty"`
+	Time     time.Time `json:"time"`
+	Count    int       `json:"count"` // 使用次数
+	Starred  bool      `json:"starred,omitempty"`
+}
+
+// History 保存在单个 JSON 文件中的翻译历史，最近使用的在前
+type History struct {
+	Path       string
+	MaxEntries int
+}
+
+// NewHistory 创建翻译历史，保存在 dir/history.json
+func NewHistory(dir string, config HistoryConfig) *History {
+	maxEntries := config.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultHistoryMaxEntries
+	}
+	return &History{
+		Path:       filepath.Join(dir, "history.json"),
+		MaxEntries: maxEntries,
+	}
+}
+
+// Entries 返回所有历史记录，文件不存在时返回空
+func (h *History) Entries() ([]HistoryEntry, error) {
+	data, err := os.ReadFile(h.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []HistoryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Add 记录一次翻译，同一查询和结果只保留一条并移到最前
+func (h *History) Add(query, result, provider string) (*HistoryEntry, error) {
+	entries, err := h.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := HistoryEntry{
+		ID:       Md5(NormalizeQuery(query) + "\x00" + result)[:12],
+		Query:    query,
+		Result:   result,
+		Provider: provider,
+		Time:     time.Now(),
+		Count:    1,
+	}
+	for i, existing := range entries {
+		if existing.ID == entry.ID {
+			entry.Count = existing.Count + 1
+			entry.Starred = existing.Starred
+			entries = append(entries[:i], entries[i+1:]...)
+			break
+		}
+	}
+	entries = append([]HistoryEntry{entry}, entries...)
+
+	if err := h.save(h.trim(entries)); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Star 收藏或取消收藏记录
+func (h *History) Star(id string, starred bool) error {
+	entries, err := h.Entries()
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		if entries[i].ID == id {
+			entries[i].Starred = starred
+			return h.save(h.trim(entries))
+		}
+	}
+	return fmt.Errorf("history error: entry %s not found", id)
+}
+
+// Clear 清空未收藏的记录
+func (h *History) Clear() error {
+	entries, err := h.Entries()
+	if err != nil {
+		return err
+	}
+	var starred []HistoryEntry
+	for _, entry := range entries {
+		if entry.Starred {
+			starred = append(starred, entry)
+		}
+	}
+	return h.save(starred)
+}
+
+// Search 模糊搜索查询和结果，按匹配程度排序，query 为空时按时间返回全部
+func (h *History) Search(query string) ([]HistoryEntry, error) {
+	entries, err := h.Entries()
+	if err != nil {
+		return nil, err
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return entries, nil
+	}
+
+	type match struct {
+		entry HistoryEntry
+		score int
+	}
+	var matches []match
+	for _, entry := range entries {
+		score, ok := FuzzyScore(query, entry.Query)
+		if resultScore, resultOK := FuzzyScore(query, entry.Result); resultOK && (!ok || resultScore > score) {
+			score, ok = resultScore, true
+		}
+		if ok {
+			matches = append(matches, match{entry: entry, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	result := make([]HistoryEntry, len(matches))
+	for i, m := range matches {
+		result[i] = m.entry
+	}
+	return result, nil
+}
+
+// Starred 返回收藏的记录
+func (h *History) Starred() ([]HistoryEntry, error) {
+	entries, err := h.Entries()
+	if err != nil {
+		return nil, err
+	}
+	var starred []HistoryEntry
+	for _, entry := range entries {
+		if entry.Starred {
+			starred = append(starred, entry)
+		}
+	}
+	return starred, nil
+}
+
+// trim 淘汰超出数量限制的最旧的未收藏记录
+func (h *History) trim(entries []HistoryEntry) []HistoryEntry {
+	kept := entries[:0]
+	count := 0
+	for _, entry := range entries {
+		if !entry.Starred {
+			if count >= h.MaxEntries {
+				continue
+			}
+			count++
+		}
+		kept = append(kept, entry)
+	}
+	return kept
+}
+
+// save 写入历史文件，先写临时文件再重命名
+func (h *History) save(entries []HistoryEntry) error {
+	if entries == nil {
+		entries = []HistoryEntry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(h.Path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "history-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), h.Path)
+}
+
+// FuzzyScore 模糊匹配，pattern 中的字符(忽略大小写和空白)按顺序出现在 text 中即匹配
+// 连续匹配、在词首匹配和在开头匹配得分更高
+func FuzzyScore(pattern, text string) (int, bool) {
+	var needle []rune
+	for _, r := range strings.ToLower(pattern) {
+		if !unicode.IsSpace(r) {
+			needle = append(needle, r)
+		}
+	}
+	if len(needle) == 0 {
+		return 0, true
+	}
+
+	haystack := []rune(strings.ToLower(text))
+	score := 0
+	matched := 0
+	last := -2
+	for i, r := range haystack {
+		if matched == len(needle) {
+			break
+		}
+		if r != needle[matched] {
+			continue
+		}
+
+		score++
+		if i == last+1 {
+			score += 3 // 连续匹配
+		}
+		if i == 0 {
+			score += 4
+		} else if !unicode.IsLetter(haystack[i-1]) && !unicode.IsDigit(haystack[i-1]) {
+			score += 2 // 词首
+		}
+		last = i
+		matched++
+	}
+	if matched < len(needle) {
+		return 0, false
+	}
+
+	// 完全相同和越短的文本越靠前
+	if string(haystack) == string(needle) {
+		score += 10
+	}
+	return score*100 - len(haystack), true
+}
+
+// ExportCSV 以 CSV 格式导出记录: 原文,译文,服务,时间
+func ExportCSV(w io.Writer, entries []HistoryEntry) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"query", "result", "provider", "time", "count"}); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		record := []string{entry.Query, entry.Result, entry.Provider, entry.Time.Format(time.RFC3339), strconv.Itoa(entry.Count)}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ExportAnki 以 Anki 可导入的 TSV 格式导出记录: 正面(原文)、背面(译文)、标签
+func ExportAnki(w io.Writer, entries []HistoryEntry) error {
+	if _, err := fmt.Fprintln(w, "#separator:tab\n#html:false\n#tags column:3"); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		line := strings.Join([]string{
+			ankiField(entry.Query),
+			ankiField(entry.Result),
+			"translate",
+		}, "\t")
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ankiField 将字段中的制表符和换行替换为空格
+func ankiField(s string) string {
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return r == '\t' || r == '\n' || r == '\r'
+	}), " ")
+}
@@ -0,0 +1,250 @@
+package translate
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultUserAgent 默认 User-Agent
+const defaultUserAgent = "AlfredWorkflows-translate/1.0"
+
+// ProxyDirect 配置为该值时不使用代理，忽略环境变量
+const ProxyDirect = "direct"
+
+// HTTPConfig HTTP客户端配置，可在全局 http 配置中设置，也可在单个服务中覆盖
+type HTTPConfig struct {
+	Proxy     string `yaml:"proxy,omitempty"`                // 代理地址 http/https/socks5，为空时使用 HTTPS_PROXY 等环境变量，direct 表示不使用代理
+	CABundle  string `yaml:"ca_bundle,omitempty"`            // 额外信任的CA证书文件(PEM)，用于自签名证书
+	Insecure  bool   `yaml:"insecure_skip_verify,omitempty"` // 跳过证书校验，仅用于自建服务
+	UserAgent string `yaml:"user_agent,omitempty"`
+}
+
+// WithDefaults 返回用 defaults 补全未设置字段后的配置
+func (c HTTPConfig) WithDefaults(defaults HTTPConfig) HTTPConfig {
+	if c.Proxy == "" {
+		c.Proxy = defaults.Proxy
+	}
+	if c.CABundle == "" {
+		c.CABundle = defaults.CABundle
+	}
+	if !c.Insecure {
+		c.Insecure = defaults.Insecure
+	}
+	if c.UserAgent == "" {
+		c.UserAgent = defaults.UserAgent
+	}
+	return c
+}
+
+var (
+	httpClientsMu sync.Mutex
+	httpClients   = map[HTTPConfig]*http.Client{}
+)
+
+// HTTPClient 返回配置对应的共享HTTP客户端，相同配置复用连接池
+// 配置无效(如代理地址错误、CA证书无法读取)时，客户端的每个请求都返回该错误
+func HTTPClient(config HTTPConfig) *http.Client {
+	httpClientsMu.Lock()
+	defer httpClientsMu.Unlock()
+
+	if client, ok := httpClients[config]; ok {
+		return client
+	}
+	client, err := NewHTTPClient(config)
+	if err != nil {
+		client = &http.Client{Transport: errorTransport{err: err}}
+	}
+	httpClients[config] = client
+	return client
+}
+
+// NewHTTPClient 创建HTTP客户端，请求超时由调用方的 context 控制
+func NewHTTPClient(config HTTPConfig) (*http.Client, error) {
+	proxy, err := proxyFunc(config.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.Insecure}
+	if config.CABundle != "" {
+		pem, err := os.ReadFile(config.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("http config error: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("http config error: no certificates in %s", config.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          32,
+		MaxIdleConnsPerHost:   4,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	userAgent := config.UserAgent
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+	}
+
+	return &http.Client{
+		Transport: &clientTransport{
+			base:      transport,
+			userAgent: userAgent,
+			debug:     os.Getenv("alfred_debug") == "1",
+		},
+	}, nil
+}
+
+// proxyFunc 解析代理配置，为空时使用 HTTPS_PROXY/HTTP_PROXY/NO_PROXY 环境变量
+func proxyFunc(proxy string) (func(*http.Request) (*url.URL, error), error) {
+	switch proxy {
+	case "":
+		return func(req *http.Request) (*url.URL, error) {
+			proxyURL, err := http.ProxyFromEnvironment(req)
+			if proxyURL != nil {
+				proxyURL = socks5hToSocks5(proxyURL)
+			}
+			return proxyURL, err
+		}, nil
+	case ProxyDirect:
+		return nil, nil
+	}
+
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("http config error: invalid proxy %q: %w", proxy, err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("http config error: unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+	return http.ProxyURL(socks5hToSocks5(proxyURL)), nil
+}
+
+// socks5hToSocks5 将 socks5h 代理改为 socks5
+// net/http 只识别 socks5，并且总是把主机名交给代理解析，效果与 socks5h 相同
+func socks5hToSocks5(proxyURL *url.URL) *url.URL {
+	if proxyURL.Scheme != "socks5h" {
+		return proxyURL
+	}
+	mapped := *proxyURL
+	mapped.Scheme = "socks5"
+	return &mapped
+}
+
+// clientTransport 设置 User-Agent，调试模式下输出请求和响应
+type clientTransport struct {
+	base      http.RoundTripper
+	userAgent string
+	debug     bool
+}
+
+// RoundTrip 实现 http.RoundTripper 接口
+func (t *clientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+
+	if !t.debug {
+		return t.base.RoundTrip(req)
+	}
+
+	if dump, err := httputil.DumpRequestOut(req, true); err == nil {
+		fmt.Fprintf(os.Stderr, "---- request ----\n%s\n", redactDump(dump))
+	}
+	started := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "---- error (%s) ----\n%s\n", time.Since(started).Round(time.Millisecond), RedactSecrets(err.Error()))
+		return nil, err
+	}
+	if dump, err := httputil.DumpResponse(resp, true); err == nil {
+		fmt.Fprintf(os.Stderr, "---- response (%s) ----\n%s\n", time.Since(started).Round(time.Millisecond), RedactSecrets(string(dump)))
+	}
+	return resp, nil
+}
+
+// sensitiveHeader 调试输出中需要隐藏的请求头
+var sensitiveHeader = regexp.MustCompile(`(?mi)^((?:Authorization|Proxy-Authorization|Ocp-Apim-Subscription-Key):).*$`)
+
+// redactDump 隐藏请求头和请求体中的密钥
+func redactDump(dump []byte) string {
+	return RedactSecrets(string(sensitiveHeader.ReplaceAll(dump, []byte("$1 ***"))))
+}
+
+// errorTransport 配置无效时返回配置错误
+type errorTransport struct {
+	err error
+}
+
+// RoundTrip 实现 http.RoundTripper 接口
+func (t errorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, t.err
+}
+
+// HTTPStatusError 服务端返回的错误状态(5xx)
+type HTTPStatusError struct {
+	StatusCode int
+}
+
+// Error 实现 error 接口
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("http status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// doRequest 使用 client 发送请求并读取响应体，返回状态码，client 为 nil 时使用默认配置的共享客户端
+// 服务端错误(5xx)返回 *HTTPStatusError，其余状态码由调用方自行处理
+func doRequest(client *http.Client, req *http.Request) (int, []byte, error) {
+	if client == nil {
+		client = HTTPClient(HTTPConfig{})
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return resp.StatusCode, body, &HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+	return resp.StatusCode, body, nil
+}
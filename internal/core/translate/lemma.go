@@ -0,0 +1,110 @@
+package translate
+
+import "strings"
+
+// lemmaIrregular 常见不规则词形的原形，词典中没有词形变化信息时使用
+var lemmaIrregular = map[string]string{
+	"am":       "be",
+	"is":       "be",
+	"are":      "be",
+	"was":      "be",
+	"were":     "be",
+	"been":     "be",
+	"has":      "have",
+	"had":      "have",
+	"did":      "do",
+	"done":     "do",
+	"does":     "do",
+	"went":     "go",
+	"gone":     "go",
+	"ran":      "run",
+	"saw":      "see",
+	"seen":     "see",
+	"took":     "take",
+	"taken":    "take",
+	"made":     "make",
+	"came":     "come",
+	"got":      "get",
+	"gotten":   "get",
+	"gave":     "give",
+	"given":    "give",
+	"knew":     "know",
+	"known":    "know",
+	"thought":  "think",
+	"told":     "tell",
+	"found":    "find",
+	"left":     "leave",
+	"felt":     "feel",
+	"brought":  "bring",
+	"bought":   "buy",
+	"began":    "begin",
+	"begun":    "begin",
+	"wrote":    "write",
+	"written":  "write",
+	"children": "child",
+	"men":      "man",
+	"women":    "woman",
+	"feet":     "foot",
+	"teeth":    "tooth",
+	"mice":     "mouse",
+	"people":   "person",
+	"better":   "good",
+	"best":     "good",
+	"worse":    "bad",
+	"worst":    "bad",
+}
+
+// GuessLemmas 按英语构词规则推测单词可能的原形，按可能性排列
+// 如 running → run、studies → study、stopped → stop、bigger → big
+func GuessLemmas(word string) []string {
+	word = strings.ToLower(word)
+	if lemma, ok := lemmaIrregular[word]; ok {
+		return []string{lemma}
+	}
+
+	var candidates []string
+	add := func(stem string) {
+		if len(stem) >= 2 && stem != word && !containsString(candidates, stem) {
+			candidates = append(candidates, stem)
+		}
+	}
+	// undouble 去掉重复的末尾辅音，如 runn → run
+	undouble := func(stem string) {
+		n := len(stem)
+		if n >= 3 && stem[n-1] == stem[n-2] && !strings.ContainsRune("aeiou", rune(stem[n-1])) {
+			add(stem[:n-1])
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ies"):
+		add(strings.TrimSuffix(word, "ies") + "y")
+	case strings.HasSuffix(word, "ves"):
+		add(strings.TrimSuffix(word, "ves") + "f")
+		add(strings.TrimSuffix(word, "ves") + "fe")
+	case strings.HasSuffix(word, "es"):
+		// boxes → box、watches → watch，其余如 makes → make
+		stem := strings.TrimSuffix(word, "es")
+		if strings.HasSuffix(stem, "s") || strings.HasSuffix(stem, "x") || strings.HasSuffix(stem, "z") ||
+			strings.HasSuffix(stem, "ch") || strings.HasSuffix(stem, "sh") || strings.HasSuffix(stem, "o") {
+			add(stem)
+		}
+		add(strings.TrimSuffix(word, "s"))
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		add(strings.TrimSuffix(word, "s"))
+	}
+
+	for _, suffix := range []string{"ing", "ed", "er", "est"} {
+		if !strings.HasSuffix(word, suffix) {
+			continue
+		}
+		stem := strings.TrimSuffix(word, suffix)
+		if suffix != "ing" && strings.HasSuffix(stem, "i") {
+			add(strings.TrimSuffix(stem, "i") + "y") // studied → study、happier → happy
+		}
+		undouble(stem)
+		add(stem)
+		add(stem + "e") // making → make、used → use
+	}
+	return candidates
+}
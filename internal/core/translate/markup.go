@@ -0,0 +1,478 @@
+package translate
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 标记语言类型
+const (
+	MarkupAuto     = "auto" // 根据查询内容自动识别(默认)
+	MarkupHTML     = "html"
+	MarkupXML      = "xml"
+	MarkupMarkdown = "markdown"
+	MarkupOff      = "off" // 不识别标记，按纯文本翻译
+)
+
+var (
+	// markupTagPattern HTML/XML 标签或注释
+	markupTagPattern = regexp.MustCompile(`<!--[\s\S]*?-->|</?[a-zA-Z][\w:.-]*(?:\s[^<>]*)?/?>`)
+	// markdownPattern Markdown 的块标记、链接、图片、行内代码或加粗
+	markdownPattern = regexp.MustCompile("(?m)^\\s*(?:#{1,6}\\s|[-*+]\\s|\\d+\\.\\s|>|```|~~~)|!?\\[[^\\]\\n]*\\]\\([^)\\s]+[^)]*\\)|`[^`\\n]+`|\\*\\*[^*\\n]+\\*\\*")
+	// markdownProtectedPattern Markdown 中不翻译的部分：图片、链接地址、行内代码、自动链接、行内 HTML、网址、引用链接定义
+	markdownProtectedPattern = regexp.MustCompile("!\\[[^\\]\\n]*\\]\\([^)\\n]*\\)|\\]\\([^)\\n]*\\)|`[^`\\n]+`|<https?://[^>\\s]+>|<!--[\\s\\S]*?-->|</?[a-zA-Z][\\w-]*(?:\\s[^<>]*)?/?>|https?://[^\\s)<>]+|(?m)^\\s*\\[[^\\]\\n]+\\]:\\s+\\S.*$")
+	// resultTagPattern 译文中的标签
+	resultTagPattern = regexp.MustCompile(`<[^>]*>`)
+	// markupPlaceholderPattern 标记占位符，兼容服务改为其他括号的情况
+	markupPlaceholderPattern = regexp.MustCompile(`[⟨〈]\s*(\d+)\s*[⟩〉]`)
+	// markupTagNamePattern 标签名
+	markupTagNamePattern = regexp.MustCompile(`^</?([a-zA-Z][\w:.-]*)`)
+	// markupNoTranslatePattern 标记为不翻译的元素
+	markupNoTranslatePattern = regexp.MustCompile(`(?i)\stranslate\s*=\s*["']?no\b|\sclass\s*=\s*["'][^"']*\bnotranslate\b`)
+)
+
+// htmlBlockTags 分隔文本段落的块级元素，其他元素作为行内元素与文字一起翻译
+var htmlBlockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "body": true, "br": true,
+	"caption": true, "dd": true, "details": true, "div": true, "dl": true, "dt": true, "figcaption": true,
+	"figure": true, "footer": true, "form": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "head": true, "header": true, "hr": true, "html": true, "li": true,
+	"main": true, "meta": true, "nav": true, "ol": true, "option": true, "p": true, "section": true,
+	"summary": true, "table": true, "tbody": true, "td": true, "tfoot": true, "th": true,
+	"thead": true, "title": true, "tr": true, "ul": true, "link": true,
+}
+
+// htmlRawTags 内容原样保留的元素，值表示是否为块级元素
+var htmlRawTags = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"pre":      true,
+	"textarea": true,
+	"code":     false,
+	"kbd":      false,
+	"samp":     false,
+}
+
+// markupTextEscaper 转义文本节点中的特殊字符
+var markupTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// DetectMarkup 识别查询的标记语言，纯文本返回空
+func DetectMarkup(query string) string {
+	trimmed := strings.TrimSpace(query)
+	hasTag := markupTagPattern.MatchString(trimmed)
+	switch {
+	case strings.HasPrefix(trimmed, "<?xml"):
+		return MarkupXML
+	case hasTag && strings.HasPrefix(trimmed, "<"):
+		return MarkupHTML
+	case markdownPattern.MatchString(trimmed):
+		return MarkupMarkdown
+	case hasTag:
+		return MarkupHTML
+	}
+	return ""
+}
+
+// stripTags 去除译文中服务添加的标签(如强调用的 <b>)，查询本身含有标签时原样返回
+func stripTags(query, text string) string {
+	if markupTagPattern.MatchString(query) {
+		return text
+	}
+	return resultTagPattern.ReplaceAllString(text, "")
+}
+
+// markupText 去除标签并解码实体，用于 Alfred 中单行显示
+func markupText(text string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(markupTagPattern.ReplaceAllString(text, " "))), " ")
+}
+
+// MarkupSupporter 能直接处理标记的服务(如 DeepL 的 tag_handling)
+// 支持时标记类型通过 context 传给服务，查询原样发送
+type MarkupSupporter interface {
+	SupportsMarkup(kind string) bool
+}
+
+// markupContextKey context 中标记类型的键
+type markupContextKey struct{}
+
+// WithMarkup 返回携带标记类型的 context
+func WithMarkup(ctx context.Context, kind string) context.Context {
+	return context.WithValue(ctx, markupContextKey{}, kind)
+}
+
+// MarkupFromContext 返回 context 中的标记类型
+func MarkupFromContext(ctx context.Context) string {
+	kind, _ := ctx.Value(markupContextKey{}).(string)
+	return kind
+}
+
+// supportsMarkup 服务是否能直接处理该类型的标记
+func supportsMarkup(service Service, kind string) bool {
+	supporter, ok := service.(MarkupSupporter)
+	return ok && supporter.SupportsMarkup(kind)
+}
+
+// SupportsMarkup 服务是否能直接处理该类型的标记
+func (s *providerService) SupportsMarkup(kind string) bool {
+	return supportsMarkup(s.service, kind)
+}
+
+// SupportsMarkup 服务是否能直接处理该类型的标记
+func (s *ChunkedService) SupportsMarkup(kind string) bool {
+	return supportsMarkup(s.Service, kind)
+}
+
+// SupportsMarkup 服务是否能直接处理该类型的标记
+func (s *GlossaryService) SupportsMarkup(kind string) bool {
+	return supportsMarkup(s.Service, kind)
+}
+
+// markupSegment 一段需要翻译的文字，行内标签替换为占位符
+type markupSegment struct {
+	leading  string
+	text     string
+	trailing string
+	tags     map[int]string
+}
+
+// MarkupService 识别 HTML/XML/Markdown 的翻译服务，只翻译文字，标签、属性和代码原样保留
+type MarkupService struct {
+	Service Service
+	Mode    string // auto/html/xml/markdown
+}
+
+// NewMarkupService 为翻译服务添加标记识别
+func NewMarkupService(service Service, mode string) *MarkupService {
+	if mode == "" {
+		mode = MarkupAuto
+	}
+	return &MarkupService{
+		Service: service,
+		Mode:    mode,
+	}
+}
+
+// SupportsGlossary 服务是否支持原生术语表
+func (s *MarkupService) SupportsGlossary() bool {
+	supporter, ok := s.Service.(GlossarySupporter)
+	return ok && supporter.SupportsGlossary()
+}
+
+// Translate 按标记类型翻译，纯文本直接交给服务翻译
+func (s *MarkupService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
+	kind := s.Mode
+	if kind == MarkupAuto {
+		kind = DetectMarkup(query)
+	}
+
+	switch {
+	case kind == "" || kind == MarkupOff:
+		return s.Service.Translate(ctx, query)
+	case supportsMarkup(s.Service, kind):
+		results, err := s.Service.Translate(WithMarkup(ctx, kind), query)
+		for i := range results {
+			results[i].Title = markupText(results[i].Title)
+		}
+		return results, err
+	case kind == MarkupMarkdown:
+		return s.translateMarkdown(ctx, query)
+	default:
+		return s.translateTags(ctx, query, kind)
+	}
+}
+
+// translateMarkdown 将链接地址、图片、代码等替换为占位符后翻译
+func (s *MarkupService) translateMarkdown(ctx context.Context, query string) ([]TranslationResult, error) {
+	protected := newMarkupTags(query)
+	masked := markdownProtectedPattern.ReplaceAllStringFunc(query, func(match string) string {
+		// 链接只保留地址，链接文字继续翻译
+		prefix := ""
+		if strings.HasPrefix(match, "](") {
+			prefix, match = "]", match[1:]
+		}
+		return prefix + protected.add(match)
+	})
+
+	results, err := s.Service.Translate(ctx, masked)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Title = restoreMarkup(results[i].Title, protected.values, false)
+		results[i].Value = restoreMarkup(results[i].Value, protected.values, true)
+		results[i].Subtitle = restoreMarkup(results[i].Subtitle, protected.values, false)
+	}
+	return results, nil
+}
+
+// translateTags 拆分标签和文字，按块级元素分段翻译文字后重组
+func (s *MarkupService) translateTags(ctx context.Context, query, kind string) ([]TranslationResult, error) {
+	parts, segments := splitMarkup(query, kind)
+	if len(segments) == 0 {
+		return s.Service.Translate(ctx, query)
+	}
+
+	texts := make([]string, len(segments))
+	for i, segment := range segments {
+		texts[i] = segment.text
+	}
+	translations, first, err := TranslateLines(ctx, s.Service, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	for _, part := range parts {
+		if !strings.HasPrefix(part, segmentMarker) {
+			b.WriteString(part)
+			continue
+		}
+		i, _ := strconv.Atoi(strings.TrimPrefix(part, segmentMarker))
+		segment := segments[i]
+		b.WriteString(segment.leading)
+		b.WriteString(restoreMarkup(markupTextEscaper.Replace(translations[i]), segment.tags, true))
+		b.WriteString(segment.trailing)
+	}
+	value := b.String()
+
+	return []TranslationResult{{
+		Title:    markupText(value),
+		Subtitle: querySubtitle(first.Subtitle, markupText(query)),
+		Value:    value,
+		Provider: first.Provider,
+
+		SourceLang: first.SourceLang,
+	}}, nil
+}
+
+// segmentMarker 拆分结果中代表第 n 段文字的标记
+const segmentMarker = "\x00segment:"
+
+// splitMarkup 拆分 HTML/XML 为原样保留的部分和需要翻译的文字段
+// parts 中以 segmentMarker 开头的项代表对应序号的文字段
+func splitMarkup(query, kind string) ([]string, []markupSegment) {
+	var parts []string
+	var segments []markupSegment
+	var pending []string  // 当前段落中的文字(已解码实体)和行内标签占位符
+	var original []string // 与 pending 对应的原文
+	pendingTags := newMarkupTags(query)
+
+	flush := func() {
+		defer func() { pending, original, pendingTags = nil, nil, newMarkupTags(query) }()
+		if !hasTranslatableText(pending) {
+			parts = append(parts, restoreMarkup(strings.Join(original, ""), pendingTags.values, false))
+			return
+		}
+		text := strings.Join(pending, "")
+		core := strings.TrimSpace(text)
+		start := strings.Index(text, core)
+		segments = append(segments, markupSegment{
+			leading:  restoreMarkup(text[:start], pendingTags.values, false),
+			text:     strings.Join(strings.Fields(core), " "),
+			trailing: restoreMarkup(text[start+len(core):], pendingTags.values, false),
+			tags:     pendingTags.values,
+		})
+		parts = append(parts, segmentMarker+strconv.Itoa(len(segments)-1))
+	}
+	inline := func(tag string) {
+		placeholder := pendingTags.add(tag)
+		pending = append(pending, placeholder)
+		original = append(original, placeholder)
+	}
+
+	tokens := tokenizeMarkup(query)
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if !isMarkupTag(token) {
+			pending = append(pending, html.UnescapeString(token))
+			original = append(original, token)
+			continue
+		}
+
+		name := markupTagName(token)
+		closing := strings.HasPrefix(token, "</")
+		raw, isRaw := htmlRawTags[name]
+		if kind == MarkupXML {
+			raw, isRaw = true, false
+		}
+		if !closing && !strings.HasSuffix(token, "/>") && (isRaw || markupNoTranslatePattern.MatchString(token)) {
+			// 不翻译的元素连同内容作为一个整体
+			end := matchingClose(tokens, i, name)
+			token = strings.Join(tokens[i:end+1], "")
+			i = end
+		}
+
+		switch {
+		case name == "" || kind == MarkupXML || htmlBlockTags[name] || (isRaw && raw):
+			flush()
+			parts = append(parts, token)
+		default:
+			inline(token)
+		}
+	}
+	flush()
+	return parts, segments
+}
+
+// hasTranslatableText 段落中去掉占位符后是否有文字
+func hasTranslatableText(pending []string) bool {
+	text := markupPlaceholderPattern.ReplaceAllString(strings.Join(pending, ""), "")
+	return strings.IndexFunc(text, unicode.IsLetter) >= 0
+}
+
+// tokenizeMarkup 拆分标签、注释和文字，标签中引号内的 > 不作为结束
+func tokenizeMarkup(text string) []string {
+	var tokens []string
+	start := 0
+	for i := 0; i < len(text); i++ {
+		if text[i] != '<' || i+1 >= len(text) {
+			continue
+		}
+		next := text[i+1]
+		if !(next == '/' || next == '!' || next == '?' || (next|0x20 >= 'a' && next|0x20 <= 'z')) {
+			continue
+		}
+
+		end := -1
+		if strings.HasPrefix(text[i:], "<!--") {
+			if j := strings.Index(text[i+4:], "-->"); j >= 0 {
+				end = i + 4 + j + 3
+			}
+		} else {
+			var quote byte
+			for j := i + 1; j < len(text); j++ {
+				c := text[j]
+				if quote != 0 {
+					if c == quote {
+						quote = 0
+					}
+				} else if c == '"' || c == '\'' {
+					quote = c
+				} else if c == '>' {
+					end = j + 1
+					break
+				}
+			}
+		}
+		if end < 0 {
+			break
+		}
+		if i > start {
+			tokens = append(tokens, text[start:i])
+		}
+		tokens = append(tokens, text[i:end])
+		start = end
+		i = end - 1
+	}
+	if start < len(text) {
+		tokens = append(tokens, text[start:])
+	}
+	return tokens
+}
+
+// isMarkupTag 是否为标签、注释或声明
+func isMarkupTag(token string) bool {
+	return len(token) > 1 && token[0] == '<' && token[len(token)-1] == '>'
+}
+
+// markupTagName 返回小写的标签名，注释和声明返回空
+func markupTagName(tag string) string {
+	match := markupTagNamePattern.FindStringSubmatch(tag)
+	if match == nil {
+		return ""
+	}
+	return strings.ToLower(match[1])
+}
+
+// matchingClose 返回与 tokens[open] 对应的结束标签位置，没有时返回 open
+func matchingClose(tokens []string, open int, name string) int {
+	depth := 0
+	for i := open; i < len(tokens); i++ {
+		if !isMarkupTag(tokens[i]) || markupTagName(tokens[i]) != name {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(tokens[i], "</"):
+			depth--
+		case !strings.HasSuffix(tokens[i], "/>"):
+			depth++
+		}
+		if depth == 0 {
+			return i
+		}
+	}
+	return open
+}
+
+// markupPlaceholder 返回第 i 个标记占位符
+func markupPlaceholder(i int) string {
+	return "⟨" + strconv.Itoa(i) + "⟩"
+}
+
+// markupTags 替换为占位符的标签，序号 → 标签
+// 查询中已有的占位符(如格式化占位符)的序号不分配给标签，还原时保持原样交给外层处理
+type markupTags struct {
+	values   map[int]string
+	reserved map[int]bool
+	next     int
+}
+
+// newMarkupTags 创建标签表，只保留查询中实际出现的占位符序号
+func newMarkupTags(query string) *markupTags {
+	tags := &markupTags{values: map[int]string{}, reserved: map[int]bool{}}
+	for _, match := range markupPlaceholderPattern.FindAllStringSubmatch(query, -1) {
+		if i, err := strconv.Atoi(match[1]); err == nil {
+			tags.reserved[i] = true
+		}
+	}
+	return tags
+}
+
+// add 记录标签，返回替换它的占位符
+func (t *markupTags) add(tag string) string {
+	for t.reserved[t.next] {
+		t.next++
+	}
+	i := t.next
+	t.values[i] = tag
+	t.next++
+	return markupPlaceholder(i)
+}
+
+// restoreMarkup 将占位符还原为标签，不在 tags 中的序号保持原样，complete 为 true 时把译文中丢失的标签按序号补在末尾
+func restoreMarkup(text string, tags map[int]string, complete bool) string {
+	if len(tags) == 0 {
+		return text
+	}
+	used := make(map[int]bool, len(tags))
+	text = markupPlaceholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		i, err := strconv.Atoi(markupPlaceholderPattern.FindStringSubmatch(match)[1])
+		tag, ok := tags[i]
+		if err != nil || !ok {
+			return match
+		}
+		if used[i] {
+			return ""
+		}
+		used[i] = true
+		return tag
+	})
+	if complete {
+		indexes := make([]int, 0, len(tags))
+		for i := range tags {
+			if !used[i] {
+				indexes = append(indexes, i)
+			}
+		}
+		sort.Ints(indexes)
+		for _, i := range indexes {
+			text += tags[i]
+		}
+	}
+	return text
+}
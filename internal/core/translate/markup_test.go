@@ -0,0 +1,82 @@
+package translate
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestNewMarkupTags(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string // 依次添加两个标签得到的占位符
+	}{
+		{name: "没有已有占位符", query: "hello", want: []string{"⟨0⟩", "⟨1⟩"}},
+		{name: "跳过已有的序号", query: "⟨0⟩ and 〈2〉", want: []string{"⟨1⟩", "⟨3⟩"}},
+		{name: "序号很大时不分配中间的序号", query: "⟨999999999⟩", want: []string{"⟨0⟩", "⟨1⟩"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags := newMarkupTags(tt.query)
+			got := []string{tags.add("<b>"), tags.add("</b>")}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("add() = %v, want %v", got, tt.want)
+			}
+			if len(tags.values) != 2 {
+				t.Errorf("values = %v, want 2 entries", tags.values)
+			}
+		})
+	}
+}
+
+func TestRestoreMarkup(t *testing.T) {
+	tags := map[int]string{1: "<b>", 3: "</b>"}
+	tests := []struct {
+		name     string
+		text     string
+		complete bool
+		want     string
+	}{
+		{name: "还原标签", text: "⟨1⟩粗体⟨3⟩", want: "<b>粗体</b>"},
+		{name: "兼容括号内空格和全角括号", text: "〈 1 〉粗体⟨3⟩", want: "<b>粗体</b>"},
+		{name: "保留的序号保持原样", text: "⟨0⟩ ⟨1⟩x⟨3⟩", want: "⟨0⟩ <b>x</b>"},
+		{name: "重复的占位符只还原一次", text: "⟨1⟩a⟨1⟩b⟨3⟩", want: "<b>ab</b>"},
+		{name: "丢失的标签按序号补在末尾", text: "粗体", complete: true, want: "粗体<b></b>"},
+		{name: "不补全时丢失的标签忽略", text: "粗体⟨3⟩", want: "粗体</b>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := restoreMarkup(tt.text, tags, tt.complete); got != tt.want {
+				t.Errorf("restoreMarkup(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkupServiceKeepsExistingPlaceholders(t *testing.T) {
+	// 服务原样返回查询，检查占位符的替换和还原
+	echo := serviceFunc(func(ctx context.Context, query string) ([]TranslationResult, error) {
+		return []TranslationResult{{Title: query, Value: query, Subtitle: "测试: " + query}}, nil
+	})
+	tests := []struct {
+		name  string
+		mode  string
+		query string
+	}{
+		{name: "Markdown", mode: MarkupMarkdown, query: "Open ⟨0⟩ [docs](https://example.com) with `code`"},
+		{name: "HTML", mode: MarkupHTML, query: "<p>Hello <b>⟨7⟩ world</b></p>"},
+		{name: "很大的序号", mode: MarkupHTML, query: "<p>Hello <b>⟨2147483647⟩</b> world</p>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := NewMarkupService(echo, tt.mode).Translate(context.Background(), tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 1 || results[0].Value != tt.query {
+				t.Errorf("results = %+v, want value %q", results, tt.query)
+			}
+		})
+	}
+}
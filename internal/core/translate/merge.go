@@ -0,0 +1,93 @@
+package translate
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// providerSeparator 合并结果中多个服务提供方之间的分隔符
+const providerSeparator = " · "
+
+// NormalizeText 规范化译文用于比较：忽略大小写、多余空白和结尾标点
+func NormalizeText(text string) string {
+	text = strings.ToLower(strings.Join(strings.Fields(text), " "))
+	return strings.TrimRightFunc(text, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	})
+}
+
+// MergeResults 合并多个服务的结果，groups 按服务优先级排列
+// 规范化后相同的译文合并为一项，副标题列出所有给出该译文的服务；
+// 结果按一致的服务数量降序排列，数量相同时保持服务优先级和服务内的原有顺序
+func MergeResults(groups [][]TranslationResult, query string) []TranslationResult {
+	type mergedResult struct {
+		result    TranslationResult
+		providers []string
+		order     int
+	}
+
+	var merged []*mergedResult
+	index := map[string]*mergedResult{}
+	order := 0
+	for _, group := range groups {
+		for _, result := range group {
+			order++
+			key := NormalizeText(result.Value)
+			if key == "" {
+				continue
+			}
+
+			existing, ok := index[key]
+			if !ok {
+				m := &mergedResult{result: result, order: order}
+				if result.Provider != "" {
+					m.providers = []string{result.Provider}
+				}
+				index[key] = m
+				merged = append(merged, m)
+				continue
+			}
+
+			// 同一服务的重复结果只保留第一项
+			if result.Provider == "" || containsString(existing.providers, result.Provider) {
+				continue
+			}
+			existing.providers = append(existing.providers, result.Provider)
+			if existing.result.Url == nil {
+				existing.result.Url = result.Url
+			}
+			if existing.result.Speech == nil {
+				existing.result.Speech = result.Speech
+			}
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if len(merged[i].providers) != len(merged[j].providers) {
+			return len(merged[i].providers) > len(merged[j].providers)
+		}
+		return merged[i].order < merged[j].order
+	})
+
+	results := make([]TranslationResult, 0, len(merged))
+	for _, m := range merged {
+		result := m.result
+		if len(m.providers) > 1 {
+			result.Subtitle = strings.Join(m.providers, providerSeparator) + ": " + query
+			result.Provider = strings.Join(m.providers, providerSeparator)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// containsString 检查字符串切片是否包含指定字符串
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
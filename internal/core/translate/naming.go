@@ -0,0 +1,193 @@
+package translate
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NamingConfig 变量命名模式配置
+type NamingConfig struct {
+	Disabled      bool              `yaml:"disabled,omitempty"`
+	Keyword       string            `yaml:"keyword,omitempty"`       // 以该关键字开头的查询生成变量名，默认 n:
+	StopWords     []string          `yaml:"stop_words,omitempty"`    // 额外去除的虚词
+	Abbreviations map[string]string `yaml:"abbreviations,omitempty"` // 额外的缩写，单词 → 缩写，映射为自身时取消内置缩写
+}
+
+// namingStopWords 内置的虚词，生成变量名时去除
+var namingStopWords = []string{
+	"a", "an", "the", "of", "to", "for", "in", "on", "at", "by", "with", "from",
+	"is", "are", "be", "this", "that", "its",
+}
+
+// namingAbbreviations 内置的常用缩写
+var namingAbbreviations = map[string]string{
+	"application":    "app",
+	"argument":       "arg",
+	"arguments":      "args",
+	"authentication": "auth",
+	"average":        "avg",
+	"button":         "btn",
+	"configuration":  "config",
+	"database":       "db",
+	"directory":      "dir",
+	"document":       "doc",
+	"environment":    "env",
+	"identifier":     "id",
+	"image":          "img",
+	"information":    "info",
+	"maximum":        "max",
+	"message":        "msg",
+	"minimum":        "min",
+	"number":         "num",
+	"parameter":      "param",
+	"parameters":     "params",
+	"reference":      "ref",
+	"request":        "req",
+	"response":       "resp",
+	"temporary":      "tmp",
+}
+
+// NamingStyle 变量命名风格
+type NamingStyle struct {
+	Name   string
+	Format func(words []string) string
+}
+
+// NamingStyles 支持的命名风格，按展示顺序排列
+var NamingStyles = []NamingStyle{
+	{Name: "camelCase", Format: func(words []string) string {
+		return strings.ToLower(words[0]) + joinTitle(words[1:])
+	}},
+	{Name: "PascalCase", Format: joinTitle},
+	{Name: "snake_case", Format: func(words []string) string {
+		return strings.ToLower(strings.Join(words, "_"))
+	}},
+	{Name: "kebab-case", Format: func(words []string) string {
+		return strings.ToLower(strings.Join(words, "-"))
+	}},
+	{Name: "CONSTANT_CASE", Format: func(words []string) string {
+		return strings.ToUpper(strings.Join(words, "_"))
+	}},
+}
+
+// IdentifierForm 一种命名风格的变量名
+type IdentifierForm struct {
+	Style string
+	Value string
+}
+
+// Namer 将英文文本转换为变量名
+type Namer struct {
+	stopWords     map[string]bool
+	abbreviations map[string]string
+}
+
+// NewNamer 创建变量名生成器，在内置虚词和缩写的基础上合并配置
+func NewNamer(config NamingConfig) *Namer {
+	namer := &Namer{
+		stopWords:     map[string]bool{},
+		abbreviations: map[string]string{},
+	}
+	for _, word := range namingStopWords {
+		namer.stopWords[word] = true
+	}
+	for _, word := range config.StopWords {
+		namer.stopWords[strings.ToLower(word)] = true
+	}
+	for word, abbr := range namingAbbreviations {
+		namer.abbreviations[word] = abbr
+	}
+	for word, abbr := range config.Abbreviations {
+		namer.abbreviations[strings.ToLower(word)] = strings.ToLower(abbr)
+	}
+	return namer
+}
+
+// Words 拆分文本为小写单词，去除虚词并应用缩写
+// 文本中含有非英文字母(如中文、音标)时返回空，全部是虚词时保留虚词
+// 变量名不能以数字开头，开头的数字去除，如 3D model → d model
+func (n *Namer) Words(text string) []string {
+	text = strings.NewReplacer("'s ", " ", "’s ", " ", "'", "", "’", "").Replace(text + " ")
+
+	var words []string
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		for _, r := range field {
+			if r > unicode.MaxASCII {
+				return nil
+			}
+		}
+		words = append(words, splitCamel(field)...)
+	}
+
+	var kept []string
+	for _, word := range words {
+		if !n.stopWords[word] {
+			kept = append(kept, word)
+		}
+	}
+	if len(kept) == 0 {
+		kept = words
+	}
+
+	for i, word := range kept {
+		if abbr, ok := n.abbreviations[word]; ok && abbr != "" {
+			kept[i] = abbr
+		}
+	}
+	for len(kept) > 0 {
+		if kept[0] = strings.TrimLeft(kept[0], "0123456789"); kept[0] != "" {
+			break
+		}
+		kept = kept[1:]
+	}
+	return kept
+}
+
+// Forms 返回文本在各命名风格下的变量名，无法生成时返回空
+func (n *Namer) Forms(text string) []IdentifierForm {
+	words := n.Words(text)
+	if len(words) == 0 {
+		return nil
+	}
+
+	forms := make([]IdentifierForm, 0, len(NamingStyles))
+	for _, style := range NamingStyles {
+		forms = append(forms, IdentifierForm{Style: style.Name, Value: style.Format(words)})
+	}
+	return forms
+}
+
+// splitCamel 拆分已有的驼峰命名并转换为小写，如 userID → user id、HTTPServer → http server
+func splitCamel(word string) []string {
+	runes := []rune(word)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		lowerToUpper := unicode.IsLower(prev) && unicode.IsUpper(cur)
+		acronymEnd := unicode.IsUpper(prev) && unicode.IsUpper(cur) && unicode.IsLower(next)
+		if lowerToUpper || acronymEnd {
+			words = append(words, strings.ToLower(string(runes[start:i])))
+			start = i
+		}
+	}
+	return append(words, strings.ToLower(string(runes[start:])))
+}
+
+// joinTitle 将单词首字母大写后连接
+func joinTitle(words []string) string {
+	var b strings.Builder
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + strings.ToLower(word[1:]))
+	}
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package translate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNamerWords(t *testing.T) {
+	tests := []struct {
+		name   string
+		config NamingConfig
+		text   string
+		want   []string
+	}{
+		{name: "去除虚词", text: "the name of the user", want: []string{"name", "user"}},
+		{name: "内置缩写", text: "database configuration", want: []string{"db", "config"}},
+		{name: "拆分驼峰", text: "userID HTTPServer", want: []string{"user", "id", "http", "server"}},
+		{name: "所有格", text: "user's profile", want: []string{"user", "profile"}},
+		{name: "全部是虚词时保留", text: "to be", want: []string{"to", "be"}},
+		{name: "含有中文", text: "user 用户", want: nil},
+		{name: "开头的数字去除", text: "3D model", want: []string{"d", "model"}},
+		{name: "开头的纯数字单词去除", text: "2024 annual report", want: []string{"annual", "report"}},
+		{name: "中间的数字保留", text: "http2 connection", want: []string{"http2", "connection"}},
+		{name: "只有数字", text: "404", want: []string{}},
+		{name: "配置的虚词", config: NamingConfig{StopWords: []string{"Get"}}, text: "get user", want: []string{"user"}},
+		{name: "取消内置缩写", config: NamingConfig{Abbreviations: map[string]string{"database": "database"}}, text: "database", want: []string{"database"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewNamer(tt.config).Words(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Words(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNamerForms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string // camelCase、PascalCase、snake_case、kebab-case、CONSTANT_CASE
+	}{
+		{text: "get user information", want: []string{"getUserInfo", "GetUserInfo", "get_user_info", "get-user-info", "GET_USER_INFO"}},
+		{text: "3D model", want: []string{"dModel", "DModel", "d_model", "d-model", "D_MODEL"}},
+		{text: "HTTPServer", want: []string{"httpServer", "HttpServer", "http_server", "http-server", "HTTP_SERVER"}},
+		{text: "用户", want: nil},
+	}
+	namer := NewNamer(NamingConfig{})
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			var got []string
+			for _, form := range namer.Forms(tt.text) {
+				got = append(got, form.Value)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Forms(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
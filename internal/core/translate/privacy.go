@@ -0,0 +1,292 @@
+package translate
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 发现敏感信息时的处理方式
+const (
+	PrivacyRedact = "redact" // 替换为占位符后发送，译文中还原(默认)
+	PrivacyLocal  = "local"  // 只使用本地词典
+	PrivacyBlock  = "block"  // 不翻译
+)
+
+// defaultPrivacyEntropy 疑似密钥的最小信息熵 比特/字符
+const defaultPrivacyEntropy = 3.5
+
+// PrivacyConfig 敏感信息检查配置
+type PrivacyConfig struct {
+	Disabled        bool     `yaml:"disabled,omitempty"`
+	Action          string   `yaml:"action,omitempty"`           // redact(默认)/local/block
+	InternalDomains []string `yaml:"internal_domains,omitempty"` // 内部域名，包括其子域名
+	Entropy         float64  `yaml:"entropy,omitempty"`          // 疑似密钥的最小信息熵，默认 3.5，小于 0 时不检查
+	Allow           []string `yaml:"allow,omitempty"`            // 不视为敏感信息的文本，如公开的联系邮箱
+}
+
+// PrivacyFinding 查询中的一处敏感信息
+type PrivacyFinding struct {
+	Kind  string // 类型说明，如 邮箱、GitHub 令牌
+	Text  string
+	start int
+	end   int
+}
+
+// privacyDetector 按正则识别的敏感信息
+type privacyDetector struct {
+	kind    string
+	pattern *regexp.Regexp
+	valid   func(string) bool // 为空时匹配即有效
+}
+
+// privacyDetectors 内置的敏感信息规则，靠前的优先
+var privacyDetectors = []privacyDetector{
+	{kind: "私钥", pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?(?:-----END [A-Z ]*PRIVATE KEY-----|$)`)},
+	{kind: "AWS 密钥", pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{kind: "GitHub 令牌", pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{kind: "JWT", pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]+`)},
+	{kind: "邮箱", pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)},
+	{kind: "身份证号", pattern: regexp.MustCompile(`\b\d{17}[\dXx]\b`), valid: validChineseID},
+	{kind: "手机号", pattern: regexp.MustCompile(`(?:\+?86[- ]?)?\b1[3-9]\d{9}\b`)},
+	{kind: "内网 IP", pattern: regexp.MustCompile(`\b(?:10(?:\.\d{1,3}){3}|192\.168(?:\.\d{1,3}){2}|172\.(?:1[6-9]|2\d|3[01])(?:\.\d{1,3}){2})\b`)},
+}
+
+// privacyTokenPattern 可能是密钥的连续字符
+var privacyTokenPattern = regexp.MustCompile(`[A-Za-z0-9+_\-]{20,}={0,2}`)
+
+// PrivacyGuard 检查查询中的敏感信息
+type PrivacyGuard struct {
+	Action    string
+	entropy   float64
+	detectors []privacyDetector
+	allow     map[string]bool
+}
+
+// NewPrivacyGuard 创建敏感信息检查，配置为禁用时返回 nil
+func NewPrivacyGuard(config PrivacyConfig) *PrivacyGuard {
+	if config.Disabled {
+		return nil
+	}
+	guard := &PrivacyGuard{
+		Action:    config.Action,
+		entropy:   config.Entropy,
+		detectors: append([]privacyDetector{}, privacyDetectors...),
+		allow:     map[string]bool{},
+	}
+	if guard.Action == "" {
+		guard.Action = PrivacyRedact
+	}
+	if guard.entropy == 0 {
+		guard.entropy = defaultPrivacyEntropy
+	}
+	for _, domain := range config.InternalDomains {
+		domain = strings.Trim(strings.TrimSpace(domain), ".")
+		if domain == "" {
+			continue
+		}
+		guard.detectors = append(guard.detectors, privacyDetector{
+			kind:    "内部域名",
+			pattern: regexp.MustCompile(`(?i)\b(?:[a-z0-9-]+\.)*` + regexp.QuoteMeta(domain) + `\b`),
+		})
+	}
+	for _, text := range config.Allow {
+		guard.allow[strings.ToLower(text)] = true
+	}
+	return guard
+}
+
+// Scan 返回查询中的敏感信息，按出现位置排列，重叠时保留先匹配的规则
+func (g *PrivacyGuard) Scan(query string) []PrivacyFinding {
+	if g == nil {
+		return nil
+	}
+
+	var findings []PrivacyFinding
+	overlaps := func(start, end int) bool {
+		for _, f := range findings {
+			if start < f.end && end > f.start {
+				return true
+			}
+		}
+		return false
+	}
+	add := func(kind string, start, end int) {
+		text := query[start:end]
+		if !g.allow[strings.ToLower(text)] && !overlaps(start, end) {
+			findings = append(findings, PrivacyFinding{Kind: kind, Text: text, start: start, end: end})
+		}
+	}
+
+	for _, detector := range g.detectors {
+		for _, loc := range detector.pattern.FindAllStringIndex(query, -1) {
+			if detector.valid == nil || detector.valid(query[loc[0]:loc[1]]) {
+				add(detector.kind, loc[0], loc[1])
+			}
+		}
+	}
+	if g.entropy > 0 {
+		for _, loc := range privacyTokenPattern.FindAllStringIndex(query, -1) {
+			if token := query[loc[0]:loc[1]]; looksLikeSecret(token, g.entropy) {
+				add("疑似密钥", loc[0], loc[1])
+			}
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool { return findings[i].start < findings[j].start })
+	return findings
+}
+
+// PrivacyKinds 返回发现的敏感信息类型，去重后按出现顺序排列
+func PrivacyKinds(findings []PrivacyFinding) []string {
+	var kinds []string
+	for _, f := range findings {
+		if !containsString(kinds, f.Kind) {
+			kinds = append(kinds, f.Kind)
+		}
+	}
+	return kinds
+}
+
+// privacyPlaceholderPattern 敏感信息占位符
+var privacyPlaceholderPattern = regexp.MustCompile(`⦃\s*(\d+)\s*⦄`)
+
+// privacyPlaceholder 返回第 i 个敏感信息占位符
+func privacyPlaceholder(i int) string {
+	return "⦃" + strconv.Itoa(i) + "⦄"
+}
+
+// Redact 将敏感信息替换为占位符，返回替换后的查询和原文
+func (g *PrivacyGuard) Redact(query string, findings []PrivacyFinding) (string, []string) {
+	var b strings.Builder
+	originals := make([]string, 0, len(findings))
+	last := 0
+	for i, f := range findings {
+		b.WriteString(query[last:f.start])
+		b.WriteString(privacyPlaceholder(i))
+		originals = append(originals, f.Text)
+		last = f.end
+	}
+	b.WriteString(query[last:])
+	return b.String(), originals
+}
+
+// restorePrivacy 将占位符还原为原文
+func restorePrivacy(text string, originals []string) string {
+	return privacyPlaceholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		i, err := strconv.Atoi(privacyPlaceholderPattern.FindStringSubmatch(match)[1])
+		if err != nil || i >= len(originals) {
+			return match
+		}
+		return originals[i]
+	})
+}
+
+// looksLikeSecret 同时含有字母和数字且信息熵足够高的字符串视为密钥，由单词组成的标识符除外
+func looksLikeSecret(token string, threshold float64) bool {
+	hasLetter := strings.IndexFunc(token, unicode.IsLetter) >= 0
+	hasDigit := strings.IndexAny(token, "0123456789") >= 0
+	return hasLetter && hasDigit && shannonEntropy(token) >= threshold && !looksLikeIdentifier(token)
+}
+
+// minIdentifierWordLength 标识符按驼峰、数字和分隔符拆分后单词的最小平均长度
+const minIdentifierWordLength = 4
+
+// upperRunPattern 连续的大写字母，驼峰命名中的缩写通常不超过5个字母
+var upperRunPattern = regexp.MustCompile(`[A-Z]{6,}`)
+
+// looksLikeIdentifier 是否为代码中的标识符，如 getUserAccountBalance2024、Windows10EnterpriseEdition
+// 随机生成的密钥按大小写和数字拆分后多为一两个字母的片段，标识符则由较长的单词组成
+func looksLikeIdentifier(token string) bool {
+	// 大小写混合时出现较长的大写片段，不像驼峰命名；全部大写的常量名不受影响
+	if strings.IndexFunc(token, unicode.IsLower) >= 0 && upperRunPattern.MatchString(token) {
+		return false
+	}
+	letters, words := 0, 0
+	for _, field := range strings.FieldsFunc(token, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		for _, word := range splitCamel(field) {
+			letters += len(word)
+			words++
+		}
+	}
+	return words > 0 && letters >= minIdentifierWordLength*words
+}
+
+// shannonEntropy 计算字符串的信息熵 比特/字符
+func shannonEntropy(s string) float64 {
+	counts := map[rune]int{}
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	entropy := 0.0
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// validChineseID 校验18位身份证号的校验码
+func validChineseID(id string) bool {
+	if len(id) != 18 {
+		return false
+	}
+	weights := []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	sum := 0
+	for i, w := range weights {
+		if id[i] < '0' || id[i] > '9' {
+			return false
+		}
+		sum += int(id[i]-'0') * w
+	}
+	return "10X98765432"[sum%11] == strings.ToUpper(id[17:])[0]
+}
+
+// PrivacyService 发送到在线服务前检查敏感信息的翻译服务
+// redact 时替换为占位符后翻译并在结果中还原，local/block 时不发送并返回 ErrorPrivacy
+type PrivacyService struct {
+	Service Service
+	Guard   *PrivacyGuard
+}
+
+// NewPrivacyService 为在线翻译服务添加敏感信息检查
+func NewPrivacyService(service Service, guard *PrivacyGuard) *PrivacyService {
+	return &PrivacyService{
+		Service: service,
+		Guard:   guard,
+	}
+}
+
+// Translate 检查敏感信息后翻译
+func (s *PrivacyService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
+	findings := s.Guard.Scan(query)
+	if len(findings) == 0 {
+		return s.Service.Translate(ctx, query)
+	}
+	if s.Guard.Action != PrivacyRedact {
+		return nil, &Error{Kind: ErrorPrivacy, Message: "查询包含" + strings.Join(PrivacyKinds(findings), "、") + "，未发送"}
+	}
+
+	redacted, originals := s.Guard.Redact(query, findings)
+	results, err := s.Service.Translate(ctx, redacted)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Title = restorePrivacy(results[i].Title, originals)
+		results[i].Value = restorePrivacy(results[i].Value, originals)
+		results[i].Subtitle = restorePrivacy(results[i].Subtitle, originals)
+		if speech := results[i].Speech; speech != nil {
+			restored := *speech
+			restored.Text = restorePrivacy(speech.Text, originals)
+			results[i].Speech = &restored
+		}
+	}
+	return results, nil
+}
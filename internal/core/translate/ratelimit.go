@@ -0,0 +1,131 @@
+package translate
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// providerRateLimits 各服务默认的每秒请求数，未列出的服务不限速
+var providerRateLimits = map[string]float64{
+	"baidu":   1, // 标准版 QPS 为 1
+	"tencent": 5,
+}
+
+// RateLimits 返回服务的每秒请求数和突发请求数，配置了 rate_limit 时优先使用
+func (c ConfigItem) RateLimits() (float64, int) {
+	rate := c.RateLimit
+	if rate == 0 {
+		rate = providerRateLimits[c.Name]
+	}
+	burst := c.Burst
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(rate)))
+	}
+	return rate, burst
+}
+
+// RateLimiter 令牌桶限速器，每秒补充 rate 个令牌，最多积累 burst 个
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter 创建令牌桶限速器，初始时令牌是满的
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait 等待并取走一个令牌，context 结束时返回错误
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		wait := l.reserve(time.Now())
+		if wait <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// reserve 按 now 补充令牌，有令牌时取走一个并返回 0，否则返回需要等待的时间
+func (l *RateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// requestTimeoutKey context 中保存延后计时的请求超时
+type requestTimeoutKey struct{}
+
+// WithRequestTimeout 设置每次请求的超时，从取得限速令牌后才开始计时
+// 批量翻译时多行共用限速器，排队等待令牌的时间不占用请求的超时
+func WithRequestTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, requestTimeoutKey{}, timeout)
+}
+
+// requestContext 为实际发送的请求应用 WithRequestTimeout 设置的超时，未设置时原样返回
+func requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout, ok := ctx.Value(requestTimeoutKey{}).(time.Duration)
+	if !ok || timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// RateLimitedService 限制请求频率的翻译服务，分段翻译的每次请求都会计数
+type RateLimitedService struct {
+	Service Service
+	Limiter *RateLimiter
+}
+
+// NewRateLimitedService 为翻译服务添加限速
+func NewRateLimitedService(service Service, limiter *RateLimiter) *RateLimitedService {
+	return &RateLimitedService{
+		Service: service,
+		Limiter: limiter,
+	}
+}
+
+// Translate 取得令牌后翻译
+func (s *RateLimitedService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
+	if err := s.Limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return s.Service.Translate(ctx, query)
+}
+
+// SupportsGlossary 服务是否支持原生术语表
+func (s *RateLimitedService) SupportsGlossary() bool {
+	supporter, ok := s.Service.(GlossarySupporter)
+	return ok && supporter.SupportsGlossary()
+}
+
+// SupportsMarkup 服务是否能直接处理该类型的标记
+func (s *RateLimitedService) SupportsMarkup(kind string) bool {
+	return supportsMarkup(s.Service, kind)
+}
@@ -0,0 +1,113 @@
+package translate
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+		steps []time.Duration // 每次取令牌距开始的时间
+		want  []time.Duration // 每次需要等待的时间
+	}{
+		{
+			name: "初始令牌用完后等待", rate: 2, burst: 2,
+			steps: []time.Duration{0, 0, 0},
+			want:  []time.Duration{0, 0, 500 * time.Millisecond},
+		},
+		{
+			name: "按时间补充令牌", rate: 2, burst: 1,
+			steps: []time.Duration{0, 250 * time.Millisecond, 500 * time.Millisecond},
+			want:  []time.Duration{0, 250 * time.Millisecond, 0},
+		},
+		{
+			name: "空闲后令牌不超过突发数", rate: 10, burst: 2,
+			steps: []time.Duration{0, 0, 10 * time.Second, 10 * time.Second, 10 * time.Second},
+			want:  []time.Duration{0, 0, 0, 0, 100 * time.Millisecond},
+		},
+		{
+			name: "每秒少于一次", rate: 0.5, burst: 1,
+			steps: []time.Duration{0, time.Second},
+			want:  []time.Duration{0, time.Second},
+		},
+		{
+			name: "突发数小于1时按1处理", rate: 1, burst: 0,
+			steps: []time.Duration{0, 0},
+			want:  []time.Duration{0, time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(tt.rate, tt.burst)
+			start := limiter.last
+			for i, step := range tt.steps {
+				got := limiter.reserve(start.Add(step))
+				if diff := got - tt.want[i]; diff < -time.Millisecond || diff > time.Millisecond {
+					t.Errorf("reserve #%d at %v = %v, want %v", i, step, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterWaitCanceled(t *testing.T) {
+	limiter := NewRateLimiter(0.001, 1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Wait() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestConfigItemRateLimits(t *testing.T) {
+	tests := []struct {
+		item      ConfigItem
+		wantRate  float64
+		wantBurst int
+	}{
+		{item: ConfigItem{Name: "baidu"}, wantRate: 1, wantBurst: 1},
+		{item: ConfigItem{Name: "tencent"}, wantRate: 5, wantBurst: 5},
+		{item: ConfigItem{Name: "deepl"}, wantRate: 0, wantBurst: 1},
+		{item: ConfigItem{Name: "baidu", RateLimit: 2.5, Burst: 4}, wantRate: 2.5, wantBurst: 4},
+		{item: ConfigItem{Name: "deepl", RateLimit: 0.5}, wantRate: 0.5, wantBurst: 1},
+	}
+	for _, tt := range tests {
+		rate, burst := tt.item.RateLimits()
+		if rate != tt.wantRate || burst != tt.wantBurst {
+			t.Errorf("%+v RateLimits() = %v, %d, want %v, %d", tt.item, rate, burst, tt.wantRate, tt.wantBurst)
+		}
+	}
+}
+
+func TestRequestTimeoutAfterWait(t *testing.T) {
+	// 令牌要等约 200ms，请求超时只有 100ms，等待令牌不占用超时
+	limiter := NewRateLimiter(5, 1)
+	limiter.Wait(context.Background())
+	var deadline time.Duration
+	inner := serviceFunc(func(ctx context.Context, query string) ([]TranslationResult, error) {
+		if d, ok := ctx.Deadline(); ok {
+			deadline = time.Until(d)
+		}
+		return []TranslationResult{{Value: query}}, ctx.Err()
+	})
+	service := NewRateLimitedService(NewMeteredService(inner, NewUsage(t.TempDir()), "fake", UsageQuota{}), limiter)
+
+	timeout := 100 * time.Millisecond
+	start := time.Now()
+	if _, err := service.Translate(WithRequestTimeout(context.Background(), timeout), "hello"); err != nil {
+		t.Fatalf("Translate() error = %v, want success after waiting for token", err)
+	}
+	if elapsed := time.Since(start); elapsed < timeout {
+		t.Errorf("Translate() returned after %v, want to wait for the token longer than the timeout", elapsed)
+	}
+	if deadline <= timeout/2 || deadline > timeout {
+		t.Errorf("request deadline = %v, want about %v from the request", deadline, timeout)
+	}
+}
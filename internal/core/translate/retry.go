@@ -0,0 +1,91 @@
+package translate
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+)
+
+// defaultRetryBackoff 默认的首次重试等待时间，之后每次翻倍
+const defaultRetryBackoff = 200 * time.Millisecond
+
+// IsRetryable 判断错误是否值得重试：网络错误、单次请求超时和服务端错误(5xx)
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return true
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// RetryService 带单次超时和重试的翻译服务
+type RetryService struct {
+	Service Service
+	Timeout time.Duration // 单次请求超时，0 表示只受上层超时限制
+	Retries int           // 失败后的重试次数
+	Backoff time.Duration // 首次重试等待时间，之后每次翻倍并加入随机抖动
+}
+
+// NewRetryService 为翻译服务添加超时和重试
+func NewRetryService(service Service, timeout time.Duration, retries int) *RetryService {
+	return &RetryService{
+		Service: service,
+		Timeout: timeout,
+		Retries: retries,
+		Backoff: defaultRetryBackoff,
+	}
+}
+
+// Translate 翻译失败且错误可重试时，按指数退避重试
+func (s *RetryService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
+	var lastErr error
+	for attempt := 0; attempt <= s.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(s.backoff(attempt)):
+			case <-ctx.Done():
+				return nil, lastErr
+			}
+		}
+
+		results, err := s.attempt(ctx, query)
+		if err == nil {
+			return results, nil
+		}
+		lastErr = err
+
+		// 上层已超时或错误不可重试时直接返回
+		if ctx.Err() != nil || !IsRetryable(err) {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// attempt 执行单次翻译
+func (s *RetryService) attempt(ctx context.Context, query string) ([]TranslationResult, error) {
+	if s.Timeout <= 0 {
+		return s.Service.Translate(ctx, query)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+	return s.Service.Translate(ctx, query)
+}
+
+// backoff 第 attempt 次重试前的等待时间，在 [0.5, 1.5) 倍之间随机抖动
+func (s *RetryService) backoff(attempt int) time.Duration {
+	base := s.Backoff << (attempt - 1)
+	return time.Duration(float64(base) * (0.5 + rand.Float64()))
+}
@@ -0,0 +1,346 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// secretRefKinds 配置中的密钥引用类型：${ENV:变量名}、${FILE:文件路径}、${CMD:命令}
+var secretRefKinds = []string{"ENV", "FILE", "CMD"}
+
+// secretRef 值中的一个引用，value[start:end] 为 ${类型:参数}
+type secretRef struct {
+	start, end int
+	kind, arg  string
+}
+
+// secretTimeout 执行 CMD 引用的最长时间，服务延迟解析时不受单次查询超时的影响
+const secretTimeout = 10 * time.Second
+
+// minSecretLength 短于此长度的值不做隐藏，避免误伤普通文本
+const minSecretLength = 4
+
+// secretFields 返回服务配置中支持引用的字段
+func (c *ConfigItem) secretFields() map[string]*string {
+	return map[string]*string{
+		"token":      &c.Token,
+		"app_key":    &c.AppKey,
+		"app_secret": &c.AppSecret,
+		"url":        &c.URL,
+	}
+}
+
+// HasSecretRef 值中是否包含 ${...} 引用
+func HasSecretRef(value string) bool {
+	return strings.Contains(value, "${")
+}
+
+// HasSecretRefs 服务配置中是否有需要解析的引用
+func (c ConfigItem) HasSecretRefs() bool {
+	for _, value := range c.secretFields() {
+		if HasSecretRef(*value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveSecrets 返回引用替换为实际值后的服务配置，解析出的值会在日志和错误信息中隐藏
+func (c ConfigItem) ResolveSecrets(ctx context.Context) (ConfigItem, error) {
+	for field, value := range c.secretFields() {
+		resolved, err := ResolveSecret(ctx, *value)
+		if err != nil {
+			return c, fmt.Errorf("%s: %w", field, err)
+		}
+		*value = resolved
+	}
+	return c, nil
+}
+
+// ResolveSecret 解析值中的所有引用，没有引用时原样返回
+func ResolveSecret(ctx context.Context, value string) (string, error) {
+	if !HasSecretRef(value) {
+		return value, nil
+	}
+
+	refs, ok := parseSecretRefs(value)
+	if !ok {
+		return "", fmt.Errorf("无效的引用 %q，可用 ${ENV:变量名}、${FILE:路径}、${CMD:命令}", value)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, ref := range refs {
+		secret, err := resolveSecretRef(ctx, ref.kind, strings.TrimSpace(ref.arg))
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", value[ref.start:ref.end], err)
+		}
+		registerSecret(secret)
+		b.WriteString(value[last:ref.start])
+		b.WriteString(secret)
+		last = ref.end
+	}
+	b.WriteString(value[last:])
+	return b.String(), nil
+}
+
+// parseSecretRefs 找出值中的所有引用，参数中的花括号需成对出现，如 ${CMD:awk '{print $1}' ~/.key}
+// 有类型不支持或括号不配对的引用时 ok 为 false
+func parseSecretRefs(value string) (refs []secretRef, ok bool) {
+	for i := 0; ; {
+		j := strings.Index(value[i:], "${")
+		if j < 0 {
+			return refs, true
+		}
+		start := i + j
+		kind, _, found := strings.Cut(value[start+2:], ":")
+		if !found || !containsString(secretRefKinds, kind) {
+			return refs, false
+		}
+
+		argStart := start + 2 + len(kind) + 1
+		depth, end := 1, -1
+		for k := argStart; k < len(value) && end < 0; k++ {
+			switch value[k] {
+			case '{':
+				depth++
+			case '}':
+				if depth--; depth == 0 {
+					end = k
+				}
+			}
+		}
+		if end < 0 {
+			return refs, false
+		}
+		refs = append(refs, secretRef{start: start, end: end + 1, kind: kind, arg: value[argStart:end]})
+		i = end + 1
+	}
+}
+
+// resolveSecretRef 读取单个引用的值，去掉首尾空白
+func resolveSecretRef(ctx context.Context, kind, arg string) (string, error) {
+	switch kind {
+	case "ENV":
+		value, ok := os.LookupEnv(arg)
+		if !ok || value == "" {
+			return "", errors.New("环境变量未设置")
+		}
+		return strings.TrimSpace(value), nil
+	case "FILE":
+		data, err := os.ReadFile(expandHome(arg))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	case "CMD":
+		ctx, cancel := context.WithTimeout(ctx, secretTimeout)
+		defer cancel()
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "/bin/sh", "-c", arg)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if ctx.Err() != nil {
+				return "", errors.New("命令超时")
+			}
+			if detail := strings.TrimSpace(stderr.String()); detail != "" {
+				return "", fmt.Errorf("%v: %s", err, responseSnippet([]byte(detail)))
+			}
+			return "", err
+		}
+		// pass 等命令第一行为密码，其余行为备注
+		value, _, _ := strings.Cut(strings.TrimSpace(stdout.String()), "\n")
+		if value = strings.TrimSpace(value); value == "" {
+			return "", errors.New("命令输出为空")
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf("不支持的引用类型 %s", kind)
+}
+
+// expandHome 将 ~/ 开头的路径展开为用户主目录
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// secrets 已解析的密钥，按长度从长到短排列，输出前替换为 ***
+var secrets struct {
+	sync.RWMutex
+	values []string
+}
+
+// registerSecret 记录需要隐藏的值
+func registerSecret(value string) {
+	if utf8.RuneCountInString(value) < minSecretLength {
+		return
+	}
+	secrets.Lock()
+	defer secrets.Unlock()
+	if containsString(secrets.values, value) {
+		return
+	}
+	secrets.values = append(secrets.values, value)
+	sort.Slice(secrets.values, func(i, j int) bool { return len(secrets.values[i]) > len(secrets.values[j]) })
+}
+
+// RedactSecrets 将文本中已解析的密钥替换为 ***
+func RedactSecrets(text string) string {
+	secrets.RLock()
+	defer secrets.RUnlock()
+	for _, value := range secrets.values {
+		text = strings.ReplaceAll(text, value, "***")
+	}
+	return text
+}
+
+// redactError 返回隐藏了密钥的错误，*Error 保留类型和原始错误以便判断是否重试
+// errors.Join 合并的错误逐个隐藏后重新合并
+func redactError(err error) error {
+	if err == nil || RedactSecrets(err.Error()) == err.Error() {
+		return err
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []error
+		for _, e := range joined.Unwrap() {
+			errs = append(errs, redactError(e))
+		}
+		return errors.Join(errs...)
+	}
+	var translateErr *Error
+	if errors.As(err, &translateErr) {
+		redacted := *translateErr
+		redacted.Message = RedactSecrets(translateErr.Message)
+		redacted.Code = RedactSecrets(translateErr.Code)
+		return &redacted
+	}
+	return errors.New(RedactSecrets(err.Error()))
+}
+
+// redactWriter 写入前隐藏密钥
+type redactWriter struct {
+	w io.Writer
+}
+
+// NewRedactWriter 返回写入前隐藏已解析密钥的 Writer，用于日志输出
+func NewRedactWriter(w io.Writer) io.Writer {
+	return &redactWriter{w: w}
+}
+
+// Write 实现 io.Writer 接口
+func (r *redactWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, RedactSecrets(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// secretService 首次翻译时才解析配置中引用的翻译服务
+// 未使用的服务不会读取密钥或执行命令，能力判断使用未解析的配置创建的服务
+type secretService struct {
+	item    ConfigItem
+	shape   Service
+	mu      sync.Mutex
+	service Service          // 解析成功后创建的服务
+	pending *secretResolving // 正在进行的解析，同时翻译的查询共用
+}
+
+// secretResolving 一次解析的结果，done 关闭后可读取
+type secretResolving struct {
+	done    chan struct{}
+	service Service
+	err     error
+}
+
+// newSecretService 为含有引用的配置创建延迟解析的服务，必填字段缺失时返回 nil
+func newSecretService(item ConfigItem) Service {
+	shape := newService(item)
+	if shape == nil {
+		return nil
+	}
+	return &secretService{item: item, shape: shape}
+}
+
+// resolve 解析引用并创建实际的服务，成功后不再解析，失败时下次翻译重新解析
+// 解析在后台进行，最长 secretTimeout，不因某次查询取消或超时而中断
+func (s *secretService) resolve(ctx context.Context) (Service, error) {
+	s.mu.Lock()
+	if s.service != nil {
+		s.mu.Unlock()
+		return s.service, nil
+	}
+	resolving := s.pending
+	if resolving == nil {
+		resolving = &secretResolving{done: make(chan struct{})}
+		s.pending = resolving
+		go s.resolveSecrets(resolving)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-resolving.done:
+		return resolving.service, resolving.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// resolveSecrets 执行一次解析，成功时保存创建的服务
+func (s *secretService) resolveSecrets(resolving *secretResolving) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretTimeout)
+	defer cancel()
+
+	if item, err := s.item.ResolveSecrets(ctx); err != nil {
+		resolving.err = NewError(providerName(s.shape), ErrorAuth, "", "读取密钥失败 "+RedactSecrets(err.Error()))
+	} else if resolving.service = newService(item); resolving.service == nil {
+		resolving.err = NewError(providerName(s.shape), ErrorAuth, "", "密钥为空")
+	}
+
+	s.mu.Lock()
+	s.service = resolving.service
+	s.pending = nil
+	s.mu.Unlock()
+	close(resolving.done)
+}
+
+// Translate 解析引用后翻译，错误信息中的密钥替换为 ***
+func (s *secretService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
+	service, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results, err := service.Translate(ctx, query)
+	return results, redactError(err)
+}
+
+// Local 服务是否为本地服务
+func (s *secretService) Local() bool {
+	return IsLocal(s.shape)
+}
+
+// SupportsGlossary 服务是否支持原生术语表
+func (s *secretService) SupportsGlossary() bool {
+	supporter, ok := s.shape.(GlossarySupporter)
+	return ok && supporter.SupportsGlossary()
+}
+
+// SupportsMarkup 服务是否能直接处理该类型的标记
+func (s *secretService) SupportsMarkup(kind string) bool {
+	return supportsMarkup(s.shape, kind)
+}
@@ -0,0 +1,142 @@
+package translate
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// secretTestItem 返回地址由命令输出的 deeplx 配置，命令每次执行时在 dir/runs 中追加一行
+func secretTestItem(dir, script string) ConfigItem {
+	return ConfigItem{Name: "deeplx", URL: "${CMD:echo run >> " + filepath.Join(dir, "runs") + "; " + script + "}"}
+}
+
+// secretTestRuns 返回命令执行的次数
+func secretTestRuns(t *testing.T, dir string) int {
+	data, err := os.ReadFile(filepath.Join(dir, "runs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Count(string(data), "\n")
+}
+
+func TestSecretServiceRetriesAfterFailure(t *testing.T) {
+	dir := t.TempDir()
+	flag := filepath.Join(dir, "ok")
+	// 第一次执行失败，之后输出地址
+	service := newSecretService(secretTestItem(dir, "if [ -f "+flag+" ]; then echo http://127.0.0.1:1/translate; else touch "+flag+"; exit 1; fi")).(*secretService)
+
+	if _, err := service.resolve(context.Background()); err == nil {
+		t.Fatal("first resolve() error = nil, want failure")
+	}
+	first, err := service.resolve(context.Background())
+	if err != nil {
+		t.Fatalf("second resolve() error = %v, want retry to succeed", err)
+	}
+	again, err := service.resolve(context.Background())
+	if err != nil || again != first {
+		t.Errorf("third resolve() = %v, %v, want cached service", again, err)
+	}
+	if runs := secretTestRuns(t, dir); runs != 2 {
+		t.Errorf("command runs = %d, want 2", runs)
+	}
+}
+
+func TestSecretServiceIgnoresCallerCancel(t *testing.T) {
+	dir := t.TempDir()
+	service := newSecretService(secretTestItem(dir, "sleep 0.2; echo http://127.0.0.1:1/translate")).(*secretService)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := service.resolve(ctx); err != context.Canceled {
+		t.Fatalf("resolve() with canceled context error = %v, want %v", err, context.Canceled)
+	}
+	// 已取消的查询不影响之后的查询，共用正在进行的解析
+	if _, err := service.resolve(context.Background()); err != nil {
+		t.Fatalf("resolve() error = %v", err)
+	}
+	if runs := secretTestRuns(t, dir); runs != 1 {
+		t.Errorf("command runs = %d, want 1", runs)
+	}
+}
+
+func TestParseSecretRefs(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   []string // 各引用的 类型:参数
+		wantOK bool
+	}{
+		{name: "没有引用", value: "plain", wantOK: true},
+		{name: "环境变量", value: "${ENV:TOKEN}", want: []string{"ENV:TOKEN"}, wantOK: true},
+		{name: "多个引用", value: "https://${ENV:HOST}/${FILE:~/.path}", want: []string{"ENV:HOST", "FILE:~/.path"}, wantOK: true},
+		{name: "命令中成对的花括号", value: "${CMD:awk '{print $1}' ~/.key}", want: []string{"CMD:awk '{print $1}' ~/.key"}, wantOK: true},
+		{name: "命令中嵌套的变量", value: "${CMD:cat ${HOME}/.key}", want: []string{"CMD:cat ${HOME}/.key"}, wantOK: true},
+		{name: "不支持的类型", value: "${VAULT:deepl}", wantOK: false},
+		{name: "缺少类型", value: "${TOKEN}", wantOK: false},
+		{name: "括号不配对", value: "${CMD:awk '{print $1' ~/.key}", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs, ok := parseSecretRefs(tt.value)
+			if ok != tt.wantOK {
+				t.Fatalf("parseSecretRefs(%q) ok = %v, want %v", tt.value, ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			var got []string
+			for _, ref := range refs {
+				got = append(got, ref.kind+":"+ref.arg)
+				if full := tt.value[ref.start:ref.end]; full != "${"+ref.kind+":"+ref.arg+"}" {
+					t.Errorf("ref span = %q, want whole reference", full)
+				}
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("parseSecretRefs(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveSecretCommandWithBraces(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte("secret-from-awk comment\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ResolveSecret(context.Background(), "${CMD:awk '{print $1}' "+path+"}")
+	if err != nil {
+		t.Fatalf("ResolveSecret() error = %v", err)
+	}
+	if got != "secret-from-awk" {
+		t.Errorf("ResolveSecret() = %q, want secret-from-awk", got)
+	}
+}
+
+func TestRedactErrorJoined(t *testing.T) {
+	registerSecret("joined-secret-value")
+	err := errors.Join(
+		NewError(ProviderDeepl, ErrorAuth, "", "invalid key joined-secret-value"),
+		NewError(ProviderYoudao, ErrorNetwork, "", "timeout"),
+		errors.New("plain joined-secret-value"),
+	)
+
+	redacted := redactError(err)
+	if strings.Contains(redacted.Error(), "joined-secret-value") {
+		t.Fatalf("redactError() = %q, still contains secret", redacted)
+	}
+	// 每个错误都保留，*Error 保留类型
+	errs := Errors(redacted)
+	if len(errs) != 3 {
+		t.Fatalf("Errors(redactError()) = %d errors, want 3", len(errs))
+	}
+	if errs[0].Kind != ErrorAuth || errs[0].Message != "invalid key ***" {
+		t.Errorf("first error = %+v, want redacted auth error", errs[0])
+	}
+	if errs[1].Kind != ErrorNetwork || errs[1].Provider != ProviderYoudao {
+		t.Errorf("second error = %+v, want youdao network error", errs[1])
+	}
+}
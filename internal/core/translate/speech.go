@@ -0,0 +1,132 @@
+package translate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// defaultSpeechPlayer 默认音频播放命令（macOS）
+const defaultSpeechPlayer = "afplay"
+
+// Speech 发音信息
+type Speech struct {
+	Text  string // 朗读的文本
+	Voice string // 语音标识，如 en、zh-CHS、uk、us
+	URL   string // 在线音频地址，为空时使用本地TTS命令
+}
+
+// SpeechConfig 发音配置
+type SpeechConfig struct {
+	Player string `yaml:"player,omitempty"` // 播放命令，音频文件路径作为最后一个参数
+	TTS    string `yaml:"tts,omitempty"`    // 没有在线音频时的本地TTS命令，文本作为最后一个参数
+}
+
+// Speaker 下载、缓存并播放发音
+type Speaker struct {
+	CacheDir string
+	Player   string
+	TTS      string
+	Client   *http.Client // 为空时使用默认HTTP客户端
+}
+
+// NewSpeaker 创建发音播放器，音频缓存在 cacheDir/speech 下
+func NewSpeaker(cacheDir string, config SpeechConfig) *Speaker {
+	player := config.Player
+	if player == "" {
+		player = defaultSpeechPlayer
+	}
+	return &Speaker{
+		CacheDir: filepath.Join(cacheDir, "speech"),
+		Player:   player,
+		TTS:      config.TTS,
+	}
+}
+
+// CachePath 返回文本和语音对应的音频缓存路径
+func (s *Speaker) CachePath(text, voice string) string {
+	return filepath.Join(s.CacheDir, Md5(voice+"\x00"+text)+".mp3")
+}
+
+// Fetch 返回发音的本地音频文件，已缓存时直接复用，否则下载到缓存目录
+func (s *Speaker) Fetch(ctx context.Context, speech Speech) (string, error) {
+	path := s.CachePath(speech.Text, speech.Voice)
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+		return path, nil
+	}
+
+	if speech.URL == "" {
+		return "", fmt.Errorf("speech error: no audio url")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", speech.URL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	client := s.Client
+	if client == nil {
+		client = HTTPClient(HTTPConfig{})
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("speech error: http status %d", resp.StatusCode)
+	}
+
+	if err := os.MkdirAll(s.CacheDir, 0o755); err != nil {
+		return "", err
+	}
+
+	// 先写入临时文件再重命名，避免中断时留下不完整的缓存
+	tmp, err := os.CreateTemp(s.CacheDir, "download-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Speak 播放发音，优先使用缓存或在线音频，失败时回退到本地TTS命令
+func (s *Speaker) Speak(ctx context.Context, speech Speech) error {
+	path, err := s.Fetch(ctx, speech)
+	if err == nil {
+		return runCommand(ctx, s.Player, path)
+	}
+
+	if s.TTS == "" {
+		return err
+	}
+	return runCommand(ctx, s.TTS, speech.Text)
+}
+
+// runCommand 执行命令行，arg 作为最后一个参数
+func runCommand(ctx context.Context, command, arg string) error {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return fmt.Errorf("speech error: empty command")
+	}
+	args := append(fields[1:], arg)
+	return exec.CommandContext(ctx, fields[0], args...).Run()
+}
@@ -0,0 +1,213 @@
+package translate
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// stardictTagPattern 释义中的 HTML/XDXF 标签
+var stardictTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// StarDictInfo StarDict 词典的 .ifo 信息
+type StarDictInfo struct {
+	BookName         string
+	WordCount        int
+	IdxOffsetBits    int
+	SameTypeSequence string
+}
+
+// ReadStarDictInfo 读取 .ifo 文件
+func ReadStarDictInfo(path string) (*StarDictInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info := &StarDictInfo{IdxOffsetBits: 32}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "bookname":
+			info.BookName = value
+		case "wordcount":
+			info.WordCount, _ = strconv.Atoi(value)
+		case "idxoffsetbits":
+			if bits, err := strconv.Atoi(value); err == nil && bits == 64 {
+				info.IdxOffsetBits = 64
+			}
+		case "sametypesequence":
+			info.SameTypeSequence = value
+		}
+	}
+	return info, scanner.Err()
+}
+
+// LoadStarDict 读取 StarDict 词典(.ifo/.idx/.dict，支持 .idx.gz 和 .dict.dz)
+// 音标(t)作为音标，其余文本类释义去除标签后作为中文释义
+func LoadStarDict(ifoPath string) ([]DictEntry, error) {
+	return loadStarDict(context.Background(), ifoPath)
+}
+
+// loadStarDict 读取 StarDict 词典，ctx 取消时停止
+func loadStarDict(ctx context.Context, ifoPath string) ([]DictEntry, error) {
+	info, err := ReadStarDictInfo(ifoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	base := strings.TrimSuffix(ifoPath, ".ifo")
+	idx, err := readStarDictFile(base+".idx", base+".idx.gz")
+	if err != nil {
+		return nil, err
+	}
+	dict, err := readStarDictFile(base+".dict", base+".dict.dz")
+	if err != nil {
+		return nil, err
+	}
+
+	offsetSize := info.IdxOffsetBits / 8
+	entries := make([]DictEntry, 0, info.WordCount)
+	for pos := 0; pos < len(idx); {
+		if len(entries)%dictCheckInterval == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		end := bytes.IndexByte(idx[pos:], 0)
+		if end < 0 || pos+end+1+offsetSize+4 > len(idx) {
+			return nil, fmt.Errorf("dict error: %s.idx: truncated entry", base)
+		}
+		word := string(idx[pos : pos+end])
+		pos += end + 1
+
+		var offset uint64
+		if offsetSize == 8 {
+			offset = binary.BigEndian.Uint64(idx[pos:])
+		} else {
+			offset = uint64(binary.BigEndian.Uint32(idx[pos:]))
+		}
+		pos += offsetSize
+		size := uint64(binary.BigEndian.Uint32(idx[pos:]))
+		pos += 4
+
+		if offset+size > uint64(len(dict)) {
+			return nil, fmt.Errorf("dict error: %s.dict: entry %q out of range", base, word)
+		}
+		entry := parseStarDictData(dict[offset:offset+size], info.SameTypeSequence)
+		entry.Word = word
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// readStarDictFile 读取第一个存在的文件，.gz/.dz 文件解压后返回
+func readStarDictFile(paths ...string) ([]byte, error) {
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		if strings.HasSuffix(path, ".gz") || strings.HasSuffix(path, ".dz") {
+			reader, err := gzip.NewReader(file)
+			if err != nil {
+				return nil, fmt.Errorf("dict error: %s: %w", path, err)
+			}
+			defer reader.Close()
+			return io.ReadAll(reader)
+		}
+		return io.ReadAll(file)
+	}
+	return nil, fmt.Errorf("dict error: %s not found", paths[0])
+}
+
+// parseStarDictData 解析词条数据
+// 有 sametypesequence 时按顺序读取各字段，最后一个字段不带结束符或长度；否则每个字段以类型字符开头
+// 小写类型为以 \0 结尾的文本，大写类型为带32位长度的二进制数据
+func parseStarDictData(data []byte, sequence string) DictEntry {
+	var entry DictEntry
+	var texts []string
+
+	addField := func(fieldType byte, value []byte) {
+		switch fieldType {
+		case 't', 'y':
+			entry.Phonetic = strings.TrimSpace(string(value))
+		case 'm', 'l', 'g', 'x', 'h', 'k', 'w':
+			text := stardictTagPattern.ReplaceAllString(string(value), "")
+			if text = strings.TrimSpace(html.UnescapeString(text)); text != "" {
+				texts = append(texts, text)
+			}
+		}
+	}
+
+	readField := func(fieldType byte, last bool) bool {
+		if fieldType >= 'A' && fieldType <= 'Z' {
+			if last {
+				addField(fieldType, data)
+				data = nil
+				return true
+			}
+			if len(data) < 4 {
+				return false
+			}
+			size := int(binary.BigEndian.Uint32(data))
+			if 4+size > len(data) {
+				return false
+			}
+			addField(fieldType, data[4:4+size])
+			data = data[4+size:]
+			return true
+		}
+		if last {
+			addField(fieldType, bytes.TrimRight(data, "\x00"))
+			data = nil
+			return true
+		}
+		end := bytes.IndexByte(data, 0)
+		if end < 0 {
+			addField(fieldType, data)
+			data = nil
+			return true
+		}
+		addField(fieldType, data[:end])
+		data = data[end+1:]
+		return true
+	}
+
+	if sequence != "" {
+		for i := 0; i < len(sequence); i++ {
+			if !readField(sequence[i], i == len(sequence)-1) {
+				break
+			}
+		}
+	} else {
+		for len(data) > 0 {
+			fieldType := data[0]
+			data = data[1:]
+			if !readField(fieldType, false) {
+				break
+			}
+		}
+	}
+
+	entry.Translation = strings.Join(texts, "\n")
+	return entry
+}
@@ -0,0 +1,234 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// tencentAPIURL 腾讯机器翻译API地址
+	tencentAPIURL = "https://tmt.tencentcloudapi.com"
+	// tencentAPIVersion 腾讯机器翻译API版本
+	tencentAPIVersion = "2018-03-21"
+	// tencentDefaultRegion 默认地域
+	tencentDefaultRegion = "ap-guangzhou"
+)
+
+// tencentLanguageNames 腾讯翻译语种代码对应的名称
+var tencentLanguageNames = map[string]string{
+	"zh":    "简体中文",
+	"zh-TW": "繁体中文",
+	"en":    "英语",
+	"ja":    "日语",
+	"ko":    "韩语",
+	"fr":    "法语",
+	"es":    "西班牙语",
+	"it":    "意大利语",
+	"de":    "德语",
+	"tr":    "土耳其语",
+	"ru":    "俄语",
+	"pt":    "葡萄牙语",
+	"vi":    "越南语",
+	"id":    "印尼语",
+	"th":    "泰语",
+	"ms":    "马来语",
+	"ar":    "阿拉伯语",
+	"hi":    "印地语",
+}
+
+// TencentError 腾讯云API错误
+type TencentError struct {
+	Code    string `json:"Code"`
+	Message string `json:"Message"`
+}
+
+// TencentTranslationResult 腾讯翻译 TextTranslate 结果
+type TencentTranslationResult struct {
+	Response struct {
+		Error      *TencentError `json:"Error,omitempty"`
+		TargetText string        `json:"TargetText"`
+		Source     string        `json:"Source"`
+		Target     string        `json:"Target"`
+		RequestId  string        `json:"RequestId"`
+	} `json:"Response"`
+}
+
+// TencentLanguageDetectResult 腾讯翻译 LanguageDetect 结果
+type TencentLanguageDetectResult struct {
+	Response struct {
+		Error     *TencentError `json:"Error,omitempty"`
+		Lang      string        `json:"Lang"`
+		RequestId string        `json:"RequestId"`
+	} `json:"Response"`
+}
+
+// TencentService 腾讯机器翻译服务
+type TencentService struct {
+	SecretID  string
+	SecretKey string
+	Region    string
+	URL       string
+	Client    *http.Client // 为空时使用默认HTTP客户端
+}
+
+// NewTencentService 创建腾讯机器翻译服务
+func NewTencentService(secretID, secretKey, region string) *TencentService {
+	if region == "" {
+		region = tencentDefaultRegion
+	}
+	return &TencentService{
+		SecretID:  secretID,
+		SecretKey: secretKey,
+		Region:    region,
+		URL:       tencentAPIURL,
+	}
+}
+
+// tencentErrorKinds 腾讯云错误码前缀对应的错误类型
+var tencentErrorKinds = []struct {
+	prefix string
+	kind   ErrorKind
+}{
+	{"AuthFailure", ErrorAuth},
+	{"UnauthorizedOperation", ErrorAuth},
+	{"FailedOperation.NoFreeAmount", ErrorQuota},
+	{"FailedOperation.ServiceIsolate", ErrorQuota},
+	{"FailedOperation.UserNotRegistered", ErrorAuth},
+	{"RequestLimitExceeded", ErrorRateLimited},
+	{"LimitExceeded", ErrorRateLimited},
+	{"UnsupportedOperation.Unsupported", ErrorUnsupportedLanguage},
+	{"UnsupportedOperation.UnSupported", ErrorUnsupportedLanguage},
+	{"InternalError", ErrorBadResponse},
+}
+
+// TencentErrorFromResponse 根据腾讯云返回的错误创建错误
+func TencentErrorFromResponse(e *TencentError) *Error {
+	kind := ErrorUnknown
+	for _, k := range tencentErrorKinds {
+		if strings.HasPrefix(e.Code, k.prefix) {
+			kind = k.kind
+			break
+		}
+	}
+	return NewError(ProviderTencent, kind, e.Code, e.Message)
+}
+
+// TencentLanguageName 返回语种代码对应的名称，未知语种原样返回
+func TencentLanguageName(code string) string {
+	if name, ok := tencentLanguageNames[code]; ok {
+		return name
+	}
+	return code
+}
+
+// Translate 使用腾讯机器翻译服务翻译
+func (s *TencentService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
+	var results []TranslationResult
+
+	requestBody := map[string]interface{}{
+		"SourceText": query,
+		"Source":     "auto",
+		"Target":     tencentTargetLang(query),
+		"ProjectId":  0,
+	}
+
+	var result TencentTranslationResult
+	if err := s.call(ctx, "TextTranslate", requestBody, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Response.Error != nil {
+		return nil, TencentErrorFromResponse(result.Response.Error)
+	}
+
+	results = append(results, TranslationResult{
+		Title:    result.Response.TargetText,
+		Subtitle: fmt.Sprintf("腾讯翻译(%s): %s", TencentLanguageName(result.Response.Source), query),
+		Value:    result.Response.TargetText,
+		Provider: ProviderTencent,
+
+		SourceLang: NormalizeLang(result.Response.Source),
+	})
+
+	return results, nil
+}
+
+// tencentTargetLang 返回翻译的目标语言，源语言由服务自动识别
+// 含有汉字的文本在本地识别语种，中文译为英文，日文、韩文等译为中文，不额外调用 LanguageDetect
+func tencentTargetLang(query string) string {
+	if HasChineseChar(query) && DetectLanguage(query) == "zh" {
+		return "en"
+	}
+	return "zh"
+}
+
+// DetectLanguage 使用 LanguageDetect 接口识别文本语种，返回语种代码
+func (s *TencentService) DetectLanguage(ctx context.Context, text string) (string, error) {
+	requestBody := map[string]interface{}{
+		"Text":      text,
+		"ProjectId": 0,
+	}
+
+	var result TencentLanguageDetectResult
+	if err := s.call(ctx, "LanguageDetect", requestBody, &result); err != nil {
+		return "", err
+	}
+
+	if result.Response.Error != nil {
+		return "", TencentErrorFromResponse(result.Response.Error)
+	}
+
+	return result.Response.Lang, nil
+}
+
+// call 签名并调用腾讯云API，将响应解析到 out
+func (s *TencentService) call(ctx context.Context, action string, requestBody interface{}, out interface{}) error {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(requestBody)
+	if err != nil {
+		return err
+	}
+
+	signer := &TC3Signer{
+		SecretID:  s.SecretID,
+		SecretKey: s.SecretKey,
+		Service:   "tmt",
+		Host:      u.Host,
+	}
+	signRequest := TC3Request{
+		Method:      "POST",
+		URI:         "/",
+		ContentType: "application/json; charset=utf-8",
+		Payload:     payload,
+		Timestamp:   time.Now().Unix(),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", signRequest.ContentType)
+	req.Header.Set("Authorization", signer.Authorization(signRequest))
+	req.Header.Set("X-TC-Action", action)
+	req.Header.Set("X-TC-Version", tencentAPIVersion)
+	req.Header.Set("X-TC-Timestamp", fmt.Sprintf("%d", signRequest.Timestamp))
+	req.Header.Set("X-TC-Region", s.Region)
+
+	status, body, err := doRequest(s.Client, req)
+	if err != nil {
+		return err
+	}
+
+	return decodeJSON(status, body, out)
+}
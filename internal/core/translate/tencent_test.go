@@ -0,0 +1,201 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// tc3ExampleSigner 腾讯云签名文档中的示例密钥
+// https://cloud.tencent.com/document/api/213/30654
+var tc3ExampleSigner = &TC3Signer{
+	SecretID:  "AKIDz8krbsJ5yKBZQpn74WFkmLPx3*******",
+	SecretKey: "Gu5t9xGARNpq86cd98joQYCN3*******",
+	Service:   "cvm",
+	Host:      "cvm.tencentcloudapi.com",
+}
+
+// tc3ExampleRequest 签名文档中的示例请求 DescribeInstances，请求体中的中文按 \uXXXX 转义
+var tc3ExampleRequest = TC3Request{
+	Method:      "POST",
+	URI:         "/",
+	ContentType: "application/json; charset=utf-8",
+	Payload:     []byte(`{"Limit": 1, "Filters": [{"Values": ["\u672a\u547d\u540d"], "Name": "instance-name"}]}`),
+	Timestamp:   1551113065,
+}
+
+func TestTC3SignerDocumentExample(t *testing.T) {
+	wantCanonical := "POST\n/\n\ncontent-type:application/json; charset=utf-8\nhost:cvm.tencentcloudapi.com\n\ncontent-type;host\n" +
+		"35e9c5b0e3ae67532d3c9f17ead6c90222632e5b1ff7f6e89887f1398934f064"
+	if got := tc3ExampleSigner.CanonicalRequest(tc3ExampleRequest); got != wantCanonical {
+		t.Errorf("CanonicalRequest() =\n%s\nwant\n%s", got, wantCanonical)
+	}
+
+	wantStringToSign := "TC3-HMAC-SHA256\n1551113065\n2019-02-25/cvm/tc3_request\n" +
+		"5ffe6a04c0664d6b969fab9a13bdab201d63ee709638e2749d62a09ca18d7031"
+	if got := tc3ExampleSigner.StringToSign(tc3ExampleRequest); got != wantStringToSign {
+		t.Errorf("StringToSign() =\n%s\nwant\n%s", got, wantStringToSign)
+	}
+
+	wantSignature := "2230eefd229f582d8b1b891af7107b91597240707d778ab3738f756258d7652c"
+	if got := tc3ExampleSigner.Signature(tc3ExampleRequest); got != wantSignature {
+		t.Errorf("Signature() = %s, want %s", got, wantSignature)
+	}
+
+	wantAuthorization := "TC3-HMAC-SHA256 Credential=AKIDz8krbsJ5yKBZQpn74WFkmLPx3*******/2019-02-25/cvm/tc3_request, " +
+		"SignedHeaders=content-type;host, Signature=" + wantSignature
+	if got := tc3ExampleSigner.Authorization(tc3ExampleRequest); got != wantAuthorization {
+		t.Errorf("Authorization() =\n%s\nwant\n%s", got, wantAuthorization)
+	}
+}
+
+func TestTC3SignerDateIsUTC(t *testing.T) {
+	// 2019-02-25 23:59:59 UTC 与 2019-02-26 00:00:00 UTC
+	for timestamp, want := range map[int64]string{1551139199: "2019-02-25", 1551139200: "2019-02-26"} {
+		r := tc3ExampleRequest
+		r.Timestamp = timestamp
+		if got := tc3ExampleSigner.StringToSign(r); !strings.Contains(got, "\n"+want+"/cvm/tc3_request\n") {
+			t.Errorf("StringToSign(%d) = %q, want date %s", timestamp, got, want)
+		}
+	}
+}
+
+// tencentServer 模拟腾讯机器翻译接口，校验签名后调用 handle 返回 Response 内容
+func tencentServer(t *testing.T, handle func(action string, body map[string]interface{}) interface{}) (*httptest.Server, *[]string) {
+	t.Helper()
+	var actions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		timestamp, err := strconv.ParseInt(r.Header.Get("X-TC-Timestamp"), 10, 64)
+		if err != nil {
+			t.Errorf("X-TC-Timestamp = %q", r.Header.Get("X-TC-Timestamp"))
+		}
+		signer := &TC3Signer{SecretID: "id", SecretKey: "key", Service: "tmt", Host: r.Host}
+		want := signer.Authorization(TC3Request{
+			Method:      r.Method,
+			URI:         r.URL.Path,
+			ContentType: r.Header.Get("Content-Type"),
+			Payload:     payload,
+			Timestamp:   timestamp,
+		})
+		if got := r.Header.Get("Authorization"); got != want {
+			t.Errorf("Authorization = %q, want %q", got, want)
+		}
+		if got := r.Header.Get("X-TC-Region"); got != "ap-shanghai" {
+			t.Errorf("X-TC-Region = %q, want ap-shanghai", got)
+		}
+		if got := r.Header.Get("X-TC-Version"); got != tencentAPIVersion {
+			t.Errorf("X-TC-Version = %q", got)
+		}
+
+		action := r.Header.Get("X-TC-Action")
+		actions = append(actions, action)
+		var body map[string]interface{}
+		if err := json.Unmarshal(payload, &body); err != nil {
+			t.Errorf("invalid payload %s: %v", payload, err)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"Response": handle(action, body)})
+	}))
+	t.Cleanup(server.Close)
+	return server, &actions
+}
+
+func newTestTencentService(url string) *TencentService {
+	service := NewTencentService("id", "key", "ap-shanghai")
+	service.URL = url
+	return service
+}
+
+func TestTencentTranslate(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		detected    string
+		wantActions []string
+		wantSource  string
+		wantTarget  string
+	}{
+		{name: "英文直接译为中文", query: "hello", detected: "en", wantActions: []string{"TextTranslate"}, wantSource: "auto", wantTarget: "zh"},
+		{name: "中文译为英文", query: "你好", detected: "zh", wantActions: []string{"TextTranslate"}, wantSource: "auto", wantTarget: "en"},
+		{name: "中英混合译为英文", query: "打开 README 文件", detected: "zh", wantActions: []string{"TextTranslate"}, wantSource: "auto", wantTarget: "en"},
+		{name: "日文汉字译为中文", query: "漢字です", detected: "ja", wantActions: []string{"TextTranslate"}, wantSource: "auto", wantTarget: "zh"},
+		{name: "韩文译为中文", query: "韓國 안녕하세요", detected: "ko", wantActions: []string{"TextTranslate"}, wantSource: "auto", wantTarget: "zh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var source, target string
+			server, actions := tencentServer(t, func(action string, body map[string]interface{}) interface{} {
+				switch action {
+				case "TextTranslate":
+					source, target = body["Source"].(string), body["Target"].(string)
+					return map[string]interface{}{"TargetText": "译文", "Source": tt.detected, "Target": target, "RequestId": "2"}
+				}
+				t.Errorf("unexpected action %q", action)
+				return nil
+			})
+
+			results, err := newTestTencentService(server.URL).Translate(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("Translate() error = %v", err)
+			}
+			if strings.Join(*actions, ",") != strings.Join(tt.wantActions, ",") {
+				t.Errorf("actions = %v, want %v", *actions, tt.wantActions)
+			}
+			if source != tt.wantSource || target != tt.wantTarget {
+				t.Errorf("Source/Target = %s/%s, want %s/%s", source, target, tt.wantSource, tt.wantTarget)
+			}
+			if len(results) != 1 || results[0].Value != "译文" || results[0].Provider != ProviderTencent {
+				t.Errorf("results = %+v", results)
+			}
+		})
+	}
+}
+
+func TestTencentDetectLanguage(t *testing.T) {
+	server, _ := tencentServer(t, func(action string, body map[string]interface{}) interface{} {
+		return map[string]interface{}{"Lang": "ko", "RequestId": "1"}
+	})
+	lang, err := newTestTencentService(server.URL).DetectLanguage(context.Background(), "안녕하세요")
+	if err != nil || lang != "ko" {
+		t.Errorf("DetectLanguage() = %q, %v, want ko", lang, err)
+	}
+}
+
+func TestTencentErrors(t *testing.T) {
+	tests := []struct {
+		code string
+		kind ErrorKind
+	}{
+		{"AuthFailure.SignatureFailure", ErrorAuth},
+		{"FailedOperation.NoFreeAmount", ErrorQuota},
+		{"RequestLimitExceeded", ErrorRateLimited},
+		{"UnsupportedOperation.UnsupportedLanguage", ErrorUnsupportedLanguage},
+		{"ResourceUnavailable.ServiceIsolate", ErrorUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			server, _ := tencentServer(t, func(action string, body map[string]interface{}) interface{} {
+				return map[string]interface{}{"Error": map[string]string{"Code": tt.code, "Message": "msg"}, "RequestId": "1"}
+			})
+			service := newTestTencentService(server.URL)
+
+			// 识别失败时按中文处理，翻译接口返回的错误原样报告
+			_, err := service.Translate(context.Background(), "你好")
+			e, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("Translate() error = %v, want *Error", err)
+			}
+			if e.Kind != tt.kind || e.Code != tt.code || e.Provider != ProviderTencent {
+				t.Errorf("error = %+v, want kind %v code %s", e, tt.kind, tt.code)
+			}
+			if _, err := service.DetectLanguage(context.Background(), "你好"); err == nil {
+				t.Errorf("DetectLanguage() error = nil, want %s", tt.code)
+			}
+		})
+	}
+}
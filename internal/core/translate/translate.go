@@ -4,27 +4,32 @@ import (
 	"bytes"
 	"context"
 	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 )
 
 // YoudaoTranslationResult 有道翻译结果
 type YoudaoTranslationResult struct {
-	ErrorCode   string   `json:"errorCode"`
-	Query       string   `json:"query"`
-	Translation []string `json:"translation"`
-	L           string   `json:"l"`
-	Dict        *Dict    `json:"dict,omitempty"`
-	Webdict     *Dict    `json:"webdict,omitempty"`
-	TSpeakUrl   string   `json:"tSpeakUrl"`
-	SpeakUrl    string   `json:"speakUrl"`
+	ErrorCode   string       `json:"errorCode"`
+	Query       string       `json:"query"`
+	Translation []string     `json:"translation"`
+	L           string       `json:"l"`
+	Dict        *Dict        `json:"dict,omitempty"`
+	Webdict     *Dict        `json:"webdict,omitempty"`
+	TSpeakUrl   string       `json:"tSpeakUrl"`
+	SpeakUrl    string       `json:"speakUrl"`
+	Basic       *YoudaoBasic `json:"basic,omitempty"`
+	Web         []YoudaoWeb  `json:"web,omitempty"`
 }
 
 // Dict 表示词典和web词典的URL
@@ -34,9 +39,10 @@ type Dict struct {
 
 // DeeplxTranslationResult DeepLX翻译结果
 type DeeplxTranslationResult struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Data    string `json:"data"`
+	Code         int      `json:"code"`
+	Message      string   `json:"message"`
+	Data         string   `json:"data"`
+	Alternatives []string `json:"alternatives,omitempty"`
 }
 
 // TranslationResult 通用翻译结果
@@ -45,23 +51,137 @@ type TranslationResult struct {
 	Subtitle string
 	Value    string
 	Url      *string
+	Speech   *Speech // 发音信息，可为空
+	Provider string  // 服务提供方名称，合并结果时展示
+
+	SourceLang string `json:",omitempty"` // 服务识别的源语言，为空表示未知
 }
 
+// 服务提供方名称
+const (
+	ProviderYoudao  = "有道"
+	ProviderDeeplx  = "DeepLX"
+	ProviderDeepl   = "DeepL"
+	ProviderBaidu   = "百度"
+	ProviderTencent = "腾讯"
+	ProviderAzure   = "Azure"
+	ProviderDict    = "本地词典"
+)
+
 // Service 翻译服务接口
 type Service interface {
 	Translate(ctx context.Context, query string) ([]TranslationResult, error)
 }
 
+// NewService 根据配置项创建对应的翻译服务，配置不完整或服务未知时返回 nil
+// 服务返回的错误统一转换为带服务提供方名称的 *Error，密钥中的 ${...} 引用在首次翻译时解析
+func NewService(item ConfigItem) Service {
+	if item.HasSecretRefs() {
+		return newSecretService(item)
+	}
+	return newService(item)
+}
+
+// newService 根据已解析的配置项创建翻译服务
+func newService(item ConfigItem) Service {
+	client := HTTPClient(item.HTTP)
+	switch item.Name {
+	case "youdao":
+		if item.AppKey != "" && item.AppSecret != "" {
+			service := NewYoudaoService(item.AppKey, item.AppSecret)
+			service.Client = client
+			if item.SignType != "" {
+				service.SignType = item.SignType
+			}
+			return &providerService{provider: ProviderYoudao, service: service}
+		}
+	case "deeplx":
+		if item.URL != "" {
+			service := NewDeeplxService(item.URL, item.Token)
+			service.Client = client
+			return &providerService{provider: ProviderDeeplx, service: service}
+		}
+	case "baidu":
+		if item.AppKey != "" && item.AppSecret != "" {
+			service := NewBaiduService(item.AppKey, item.AppSecret)
+			service.Client = client
+			if item.URL != "" {
+				service.URL = item.URL
+			}
+			return &providerService{provider: ProviderBaidu, service: service}
+		}
+	case "tencent":
+		if item.AppKey != "" && item.AppSecret != "" {
+			service := NewTencentService(item.AppKey, item.AppSecret, item.Region)
+			service.Client = client
+			if item.URL != "" {
+				service.URL = item.URL
+			}
+			return &providerService{provider: ProviderTencent, service: service}
+		}
+	case "azure":
+		if item.AppKey != "" {
+			service := NewAzureService(item.AppKey, item.Region, item.Targets)
+			service.Client = client
+			if item.URL != "" {
+				service.URL = item.URL
+			}
+			return &providerService{provider: ProviderAzure, service: service}
+		}
+	case "deepl":
+		if item.Token != "" {
+			service := NewDeeplService(item.Token)
+			service.Client = client
+			if item.URL != "" {
+				service.URL = item.URL
+			}
+			if len(item.Targets) > 0 {
+				service.Targets = item.Targets
+			}
+			service.Formality = item.Formality
+			service.GlossaryID = item.GlossaryID
+			service.TagHandling = item.TagHandling
+			service.StateDir = item.StateDir
+			return &providerService{provider: ProviderDeepl, service: service}
+		}
+	case "dict":
+		if item.Path != "" {
+			return &providerService{provider: ProviderDict, service: NewDictService(item.Path)}
+		}
+	}
+	return nil
+}
+
+// LocalService 不访问网络的本地服务，如离线词典
+type LocalService interface {
+	Local() bool
+}
+
+// IsLocal 服务是否为本地服务
+func IsLocal(service Service) bool {
+	local, ok := service.(LocalService)
+	return ok && local.Local()
+}
+
+// 有道翻译签名方式
+const (
+	YoudaoSignV1 = "v1" // md5(appKey+q+salt+密钥)，旧版签名
+	YoudaoSignV3 = "v3" // sha256(appKey+input+salt+curtime+密钥)
+)
+
 // YoudaoService 有道翻译服务
 type YoudaoService struct {
 	AppKey    string
 	AppSecret string
+	SignType  string
+	Client    *http.Client // 为空时使用默认HTTP客户端
 }
 
 // DeeplxService DeepLX翻译服务
 type DeeplxService struct {
-	URL   string
-	Token string
+	URL    string
+	Token  string
+	Client *http.Client // 为空时使用默认HTTP客户端
 }
 
 // NewYoudaoService 创建有道翻译服务
@@ -69,6 +189,7 @@ func NewYoudaoService(appKey, appSecret string) *YoudaoService {
 	return &YoudaoService{
 		AppKey:    appKey,
 		AppSecret: appSecret,
+		SignType:  YoudaoSignV3,
 	}
 }
 
@@ -87,6 +208,45 @@ func Md5(str string) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+// Sha256 计算字符串的SHA256值
+func Sha256(str string) string {
+	h := sha256.New()
+	h.Write([]byte(str))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// NewUUID 生成随机的 UUID v4 字符串
+func NewUUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// YoudaoTruncate 计算有道v3签名使用的input
+// q长度大于20时为 前10个字符 + 长度 + 后10个字符，否则为q本身
+func YoudaoTruncate(q string) string {
+	runes := []rune(q)
+	size := len(runes)
+	if size <= 20 {
+		return q
+	}
+	return string(runes[:10]) + strconv.Itoa(size) + string(runes[size-10:])
+}
+
+// YoudaoSignV3Digest 计算有道v3签名 sha256(appKey+input+salt+curtime+密钥)
+func YoudaoSignV3Digest(appKey, query, salt, curtime, appSecret string) string {
+	return Sha256(appKey + YoudaoTruncate(query) + salt + curtime + appSecret)
+}
+
+// YoudaoSignV1Digest 计算有道旧版签名 md5(appKey+q+salt+密钥)
+func YoudaoSignV1Digest(appKey, query, salt, appSecret string) string {
+	return Md5(appKey + query + salt + appSecret)
+}
+
 // HasChineseChar 检查字符串是否包含中文字符
 // func HasChineseChar(str string) bool {
 // 	for _, r := range str {
@@ -102,13 +262,85 @@ func HasChineseChar(str string) bool {
 	return find
 }
 
+// langAliases 各服务语言代码与通用代码不一致的情况
+var langAliases = map[string]string{
+	"zh-chs":  "zh",
+	"zh-hans": "zh",
+	"zh-cn":   "zh",
+	"zh-cht":  "zh-hant",
+	"zh-tw":   "zh-hant",
+	"cht":     "zh-hant",
+	"jp":      "ja",
+	"kor":     "ko",
+	"fra":     "fr",
+	"spa":     "es",
+}
+
+// NormalizeLang 将服务返回的语言代码转换为小写的通用代码，如 EN → en、zh-CHS → zh、jp → ja
+func NormalizeLang(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "_", "-"))
+	if alias, ok := langAliases[code]; ok {
+		return alias
+	}
+	if base, _, found := strings.Cut(code, "-"); found && base != "zh" {
+		return base
+	}
+	return code
+}
+
+// DetectLanguage 根据文字推测语言，服务未返回源语言时使用，无法判断时返回空
+// 只含 ASCII 字母的文本视为英文
+func DetectLanguage(text string) string {
+	counts := map[string]int{}
+	ascii := 0
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			counts["ja"]++
+		case unicode.Is(unicode.Hangul, r):
+			counts["ko"]++
+		case unicode.Is(unicode.Han, r):
+			counts["zh"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			counts["ru"]++
+		case unicode.Is(unicode.Arabic, r):
+			counts["ar"]++
+		case unicode.Is(unicode.Thai, r):
+			counts["th"]++
+		case r <= unicode.MaxASCII && unicode.IsLetter(r):
+			ascii++
+		case unicode.IsLetter(r):
+			counts[""]++ // 其他拉丁字母等
+		}
+	}
+
+	// 日文中的汉字较多，有假名时即为日文
+	if counts["ja"] > 0 {
+		return "ja"
+	}
+	best, max := "", 0
+	for lang, n := range counts {
+		if n > max || (n == max && lang < best) {
+			best, max = lang, n
+		}
+	}
+	if max == 0 && ascii > 0 {
+		return "en"
+	}
+	return best
+}
+
+// IsSingleWord 检查查询是否为单个单词或词语
+func IsSingleWord(str string) bool {
+	return len(strings.Fields(str)) == 1
+}
+
 // Translate 使用有道翻译服务翻译
 func (s *YoudaoService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
 	var results []TranslationResult
 
-	salt := strconv.FormatInt(time.Now().Unix(), 10)
+	salt := NewUUID()
 	curtime := strconv.FormatInt(time.Now().Unix(), 10)
-	sign := Md5(s.AppKey + query + salt + s.AppSecret)
 
 	// to := "en"
 	// if HasChineseChar(query) {
@@ -122,9 +354,13 @@ func (s *YoudaoService) Translate(ctx context.Context, query string) ([]Translat
 	params.Add("q", query)
 	params.Add("appKey", s.AppKey)
 	params.Add("salt", salt)
-	params.Add("sign", sign)
-	// params.Add("signType", "v3")
-	params.Add("curtime", curtime)
+	if s.SignType == YoudaoSignV1 {
+		params.Add("sign", YoudaoSignV1Digest(s.AppKey, query, salt, s.AppSecret))
+	} else {
+		params.Add("sign", YoudaoSignV3Digest(s.AppKey, query, salt, curtime, s.AppSecret))
+		params.Add("signType", YoudaoSignV3)
+		params.Add("curtime", curtime)
+	}
 
 	apiURL := "https://openapi.youdao.com/api"
 	req, err := http.NewRequestWithContext(ctx, "GET", apiURL+"?"+params.Encode(), nil)
@@ -132,43 +368,69 @@ func (s *YoudaoService) Translate(ctx context.Context, query string) ([]Translat
 		return nil, err
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	status, body, err := doRequest(s.Client, req)
 	if err != nil {
 		return nil, err
 	}
 
 	var result YoudaoTranslationResult
-	if err := json.Unmarshal(body, &result); err != nil {
+	if err := decodeJSON(status, body, &result); err != nil {
 		return nil, err
 	}
 
 	if result.ErrorCode != "0" {
-		return nil, fmt.Errorf("youdao translation error: %s", result.ErrorCode)
+		return nil, YoudaoError(result.ErrorCode)
 	}
 
+	reviewUrl := ""
+	if result.Webdict != nil {
+		reviewUrl = result.Webdict.URL
+	}
+	sourceLang, targetLang := result.Languages()
 	for _, translation := range result.Translation {
-		reviewUrl := ""
-		if result.Webdict != nil {
-			reviewUrl = result.Webdict.URL
-		}
 		results = append(results, TranslationResult{
 			Title:    translation,
 			Subtitle: "有道翻译: " + query,
 			Value:    translation,
+			Provider: ProviderYoudao,
 			Url:      &reviewUrl,
+			Speech:   &Speech{Text: translation, Voice: targetLang, URL: result.TSpeakUrl},
+
+			SourceLang: NormalizeLang(sourceLang),
 		})
 	}
 
+	// 单词查询时补充词典释义
+	if IsSingleWord(query) {
+		querySpeech := &Speech{Text: query, Voice: sourceLang, URL: result.SpeakUrl}
+		for _, dictResult := range result.DictionaryResults(query, reviewUrl) {
+			if dictResult.Speech == nil {
+				dictResult.Speech = querySpeech
+			}
+			results = append(results, dictResult)
+		}
+	}
+
 	return results, nil
 }
 
+// DeeplxError 根据DeepLX返回的状态码创建错误
+func DeeplxError(code int, message string) *Error {
+	kind := ErrorUnknown
+	switch code {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		kind = ErrorAuth
+	case http.StatusTooManyRequests:
+		kind = ErrorRateLimited
+	case http.StatusServiceUnavailable:
+		kind = ErrorBadResponse
+	}
+	if message == "" {
+		message = kind.String()
+	}
+	return NewError(ProviderDeeplx, kind, strconv.Itoa(code), message)
+}
+
 // Translate 使用DeepLX翻译服务翻译
 func (s *DeeplxService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
 	var results []TranslationResult
@@ -204,35 +466,43 @@ func (s *DeeplxService) Translate(ctx context.Context, query string) ([]Translat
 		req.Header.Set("Authorization", "Bearer "+s.Token)
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	status, body, err := doRequest(s.Client, req)
 	if err != nil {
 		return nil, err
 	}
 
 	var result DeeplxTranslationResult
-	if err := json.Unmarshal(body, &result); err != nil {
+	if err := decodeJSON(status, body, &result); err != nil {
 		return nil, err
 	}
 
 	if result.Code != 200 {
-		return nil, fmt.Errorf("deeplx translation error: %s", result.Message)
+		return nil, DeeplxError(result.Code, result.Message)
 	}
 
-	// 清理结果中的HTML标签
-	cleanResult := regexp.MustCompile("<[^>]*>").ReplaceAllString(result.Data, "")
+	// 清理结果中的HTML标签，查询本身含有标签时保留
+	cleanResult := stripTags(query, result.Data)
 
 	results = append(results, TranslationResult{
 		Title:    cleanResult,
 		Subtitle: "DeepLX翻译: " + query,
 		Value:    cleanResult,
+		Provider: ProviderDeeplx,
 	})
 
+	// 备选译文作为额外结果
+	for _, alternative := range result.Alternatives {
+		cleanAlternative := stripTags(query, alternative)
+		if cleanAlternative == "" || cleanAlternative == cleanResult {
+			continue
+		}
+		results = append(results, TranslationResult{
+			Title:    cleanAlternative,
+			Subtitle: "DeepLX翻译(备选): " + query,
+			Value:    cleanAlternative,
+			Provider: ProviderDeeplx,
+		})
+	}
+
 	return results, nil
 }
@@ -0,0 +1,278 @@
+package translate
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// UsageQuota 服务每月的额度，0 表示不限
+type UsageQuota struct {
+	Chars    int `yaml:"chars,omitempty"`    // 每月字符数
+	Requests int `yaml:"requests,omitempty"` // 每月请求数
+}
+
+// Empty 是否未设置额度
+func (q UsageQuota) Empty() bool {
+	return q.Chars <= 0 && q.Requests <= 0
+}
+
+// UsageStats 服务一个月的用量
+type UsageStats struct {
+	Requests int
+	Chars    int
+}
+
+// Exceeds 用量是否已达到额度
+func (s UsageStats) Exceeds(quota UsageQuota) bool {
+	return (quota.Chars > 0 && s.Chars >= quota.Chars) || (quota.Requests > 0 && s.Requests >= quota.Requests)
+}
+
+// Remaining 返回剩余额度的说明，如 剩余12000字符，未设置额度时返回空
+func (s UsageStats) Remaining(quota UsageQuota) string {
+	remaining := func(limit, used int) int {
+		if used > limit {
+			return 0
+		}
+		return limit - used
+	}
+	var parts []string
+	if quota.Chars > 0 {
+		parts = append(parts, fmt.Sprintf("剩余%d字符", remaining(quota.Chars, s.Chars)))
+	}
+	if quota.Requests > 0 {
+		parts = append(parts, fmt.Sprintf("剩余%d次", remaining(quota.Requests, s.Requests)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Usage 按月记录各服务的请求数和字符数
+// 每个月一个追加写入的日志文件，每行 服务名\t字符数，多个进程同时写入也不会丢失记录
+// 本月用量在进程内只读取一次，之后随 Record 累加
+type Usage struct {
+	Dir     string
+	mu      sync.Mutex
+	month   string // current 对应的月份，为空时尚未读取
+	current map[string]UsageStats
+}
+
+// NewUsage 创建用量记录，保存在 dir/usage 目录
+func NewUsage(dir string) *Usage {
+	return &Usage{Dir: filepath.Join(dir, "usage")}
+}
+
+// UsageMonth 返回时间所在的月份，如 2024-05
+func UsageMonth(t time.Time) string {
+	return t.Format("2006-01")
+}
+
+// path 返回月份对应的日志文件
+func (u *Usage) path(month string) string {
+	return filepath.Join(u.Dir, month+".log")
+}
+
+// Record 记录本月一次请求
+func (u *Usage) Record(name string, chars int) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := os.MkdirAll(u.Dir, 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(u.path(UsageMonth(time.Now())), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(file, "%s\t%d\n", name, chars)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && u.month == UsageMonth(time.Now()) {
+		stats := u.current[name]
+		stats.Requests++
+		stats.Chars += chars
+		u.current[name] = stats
+	}
+	return err
+}
+
+// Month 返回指定月份各服务的用量，没有记录时返回空
+func (u *Usage) Month(month string) (map[string]UsageStats, error) {
+	stats := map[string]UsageStats{}
+	file, err := os.Open(u.path(month))
+	if os.IsNotExist(err) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		name, count, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			continue
+		}
+		chars, err := strconv.Atoi(count)
+		if err != nil {
+			continue
+		}
+		s := stats[name]
+		s.Requests++
+		s.Chars += chars
+		stats[name] = s
+	}
+	return stats, scanner.Err()
+}
+
+// Current 返回服务本月的用量，读取失败时视为没有用量
+// 日志只在进程内第一次调用或跨月时读取，其他进程之后写入的记录不会反映在结果中
+func (u *Usage) Current(name string) UsageStats {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if month := UsageMonth(time.Now()); u.month != month {
+		stats, err := u.Month(month)
+		if err != nil {
+			stats = map[string]UsageStats{}
+		}
+		u.month, u.current = month, stats
+	}
+	return u.current[name]
+}
+
+// Months 返回有记录的月份，从新到旧排列
+func (u *Usage) Months() ([]string, error) {
+	entries, err := os.ReadDir(u.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var months []string
+	for _, entry := range entries {
+		if month, ok := strings.CutSuffix(entry.Name(), ".log"); ok {
+			months = append(months, month)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(months)))
+	return months, nil
+}
+
+// MeteredService 记录用量并在额度用完时不再请求的翻译服务
+// 放在缓存、重试、限速之内，只统计实际发送的请求
+type MeteredService struct {
+	Service Service
+	Usage   *Usage
+	Name    string // 用量统计的名称，见 Config.UsageKey
+	Quota   UsageQuota
+}
+
+// NewMeteredService 为翻译服务添加用量记录和额度检查
+func NewMeteredService(service Service, usage *Usage, name string, quota UsageQuota) *MeteredService {
+	return &MeteredService{
+		Service: service,
+		Usage:   usage,
+		Name:    name,
+		Quota:   quota,
+	}
+}
+
+// Translate 额度未用完时翻译并记录用量，翻译失败的请求同样计数
+// 用量统计位于限速之内，WithRequestTimeout 设置的超时在此开始计时
+func (s *MeteredService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
+	if !s.Quota.Empty() {
+		if current := s.Usage.Current(s.Name); current.Exceeds(s.Quota) {
+			return nil, NewError(providerName(s.Service), ErrorQuota, "", "本月额度已用完")
+		}
+	}
+
+	ctx, cancel := requestContext(ctx)
+	defer cancel()
+	results, err := s.Service.Translate(ctx, query)
+	// 用量只用于提示，写入失败不影响翻译结果
+	_ = s.Usage.Record(s.Name, utf8.RuneCountInString(query))
+	return results, err
+}
+
+// SupportsGlossary 服务是否支持原生术语表
+func (s *MeteredService) SupportsGlossary() bool {
+	supporter, ok := s.Service.(GlossarySupporter)
+	return ok && supporter.SupportsGlossary()
+}
+
+// SupportsMarkup 服务是否能直接处理该类型的标记
+func (s *MeteredService) SupportsMarkup(kind string) bool {
+	return supportsMarkup(s.Service, kind)
+}
+
+// providerName 返回服务提供方名称，用于错误信息，经过包装的服务返回其内部服务的名称
+func providerName(service Service) string {
+	switch s := service.(type) {
+	case *providerService:
+		return s.provider
+	case *secretService:
+		return providerName(s.shape)
+	case *MeteredService:
+		return providerName(s.Service)
+	case *RateLimitedService:
+		return providerName(s.Service)
+	case *ChunkedService:
+		return providerName(s.Service)
+	case *GlossaryService:
+		return providerName(s.Service)
+	case *MarkupService:
+		return providerName(s.Service)
+	case *RetryService:
+		return providerName(s.Service)
+	}
+	return ""
+}
+
+// QuotaNoteService 在结果副标题中显示本月剩余额度的翻译服务
+// 放在缓存之外，命中缓存时同样显示最新的剩余额度
+type QuotaNoteService struct {
+	Service Service
+	Usage   *Usage
+	Name    string
+	Quota   UsageQuota
+}
+
+// NewQuotaNoteService 为翻译服务添加剩余额度提示
+func NewQuotaNoteService(service Service, usage *Usage, name string, quota UsageQuota) *QuotaNoteService {
+	return &QuotaNoteService{
+		Service: service,
+		Usage:   usage,
+		Name:    name,
+		Quota:   quota,
+	}
+}
+
+// Translate 翻译后在副标题的查询前插入剩余额度，如 有道翻译 [剩余12000字符]: hello
+func (s *QuotaNoteService) Translate(ctx context.Context, query string) ([]TranslationResult, error) {
+	results, err := s.Service.Translate(ctx, query)
+	if err != nil || len(results) == 0 {
+		return results, err
+	}
+
+	note := "[" + s.Usage.Current(s.Name).Remaining(s.Quota) + "]"
+	for i := range results {
+		subtitle := results[i].Subtitle
+		if j := strings.Index(subtitle, ": "); j >= 0 {
+			results[i].Subtitle = subtitle[:j] + " " + note + subtitle[j:]
+		} else {
+			results[i].Subtitle = strings.TrimSpace(subtitle + " " + note)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,126 @@
+package translate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestUsageRecordAndCurrent(t *testing.T) {
+	usage := NewUsage(t.TempDir())
+	if got := usage.Current("youdao"); got != (UsageStats{}) {
+		t.Fatalf("Current() without records = %+v, want zero", got)
+	}
+
+	usage.Record("youdao", 5)
+	usage.Record("youdao", 7)
+	usage.Record("deepl", 3)
+	if got, want := usage.Current("youdao"), (UsageStats{Requests: 2, Chars: 12}); got != want {
+		t.Errorf("Current(youdao) = %+v, want %+v", got, want)
+	}
+	if got, want := usage.Current("deepl"), (UsageStats{Requests: 1, Chars: 3}); got != want {
+		t.Errorf("Current(deepl) = %+v, want %+v", got, want)
+	}
+
+	// 累加的结果与日志文件一致
+	stats, err := usage.Month(UsageMonth(time.Now()))
+	if err != nil {
+		t.Fatalf("Month() error = %v", err)
+	}
+	if stats["youdao"] != usage.Current("youdao") || stats["deepl"] != usage.Current("deepl") {
+		t.Errorf("Month() = %+v, want same as Current()", stats)
+	}
+}
+
+func TestUsageCurrentReadsLogOnce(t *testing.T) {
+	dir := t.TempDir()
+	NewUsage(dir).Record("youdao", 4)
+
+	usage := NewUsage(dir)
+	if got, want := usage.Current("youdao"), (UsageStats{Requests: 1, Chars: 4}); got != want {
+		t.Fatalf("Current() = %+v, want %+v", got, want)
+	}
+
+	// 读取后不再重新读取日志文件
+	file, err := os.OpenFile(usage.path(UsageMonth(time.Now())), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintf(file, "youdao\t100\n")
+	file.Close()
+	if got, want := usage.Current("youdao"), (UsageStats{Requests: 1, Chars: 4}); got != want {
+		t.Errorf("Current() after external write = %+v, want cached %+v", got, want)
+	}
+}
+
+func TestUsageStatsRemaining(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats UsageStats
+		quota UsageQuota
+		want  string
+	}{
+		{name: "未设置额度", stats: UsageStats{Requests: 1, Chars: 10}, want: ""},
+		{name: "字符额度", stats: UsageStats{Chars: 300}, quota: UsageQuota{Chars: 1000}, want: "剩余700字符"},
+		{name: "超出额度显示0", stats: UsageStats{Chars: 1200, Requests: 3}, quota: UsageQuota{Chars: 1000, Requests: 10}, want: "剩余0字符 剩余7次"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stats.Remaining(tt.quota); got != tt.want {
+				t.Errorf("Remaining() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigUsageKey(t *testing.T) {
+	config := &Config{Services: []ConfigItem{
+		{Name: "youdao", AppKey: "a"},
+		{Name: "deepl", Token: "key1", Targets: []string{"ZH"}},
+		{Name: "deepl", Token: "key1", Targets: []string{"EN"}},
+		{Name: "deepl", Token: "key2"},
+		{Name: "baidu", AppKey: "b", UsageName: "baidu-free"},
+	}}
+	keys := make([]string, len(config.Services))
+	for i, item := range config.Services {
+		keys[i] = config.UsageKey(item)
+	}
+
+	// 唯一的服务沿用服务名称
+	if keys[0] != "youdao" {
+		t.Errorf("UsageKey(youdao) = %q, want youdao", keys[0])
+	}
+	// 同一账号的不同配置共用用量，不同账号分别统计
+	if keys[1] != keys[2] {
+		t.Errorf("UsageKey() of same account = %q, %q, want equal", keys[1], keys[2])
+	}
+	if keys[1] == keys[3] || keys[1] == "deepl" || keys[3] == "deepl" {
+		t.Errorf("UsageKey() of different accounts = %q, %q, want distinct per account", keys[1], keys[3])
+	}
+	if keys[4] != "baidu-free" {
+		t.Errorf("UsageKey(baidu) = %q, want usage_name", keys[4])
+	}
+}
+
+func TestMeteredServicePerInstance(t *testing.T) {
+	// 同名服务的两个账号各自统计额度，一个用完不影响另一个
+	usage := NewUsage(t.TempDir())
+	ok := serviceFunc(func(ctx context.Context, query string) ([]TranslationResult, error) {
+		return []TranslationResult{{Title: query}}, nil
+	})
+	quota := UsageQuota{Requests: 1}
+	first := NewMeteredService(ok, usage, "deepl:aaaaaaaa", quota)
+	second := NewMeteredService(ok, usage, "deepl:bbbbbbbb", quota)
+
+	if _, err := first.Translate(context.Background(), "hello"); err != nil {
+		t.Fatalf("first Translate() error = %v", err)
+	}
+	if _, err := first.Translate(context.Background(), "hello"); err == nil {
+		t.Errorf("first Translate() after quota error = nil, want quota error")
+	}
+	if _, err := second.Translate(context.Background(), "hello"); err != nil {
+		t.Errorf("second Translate() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,345 @@
+package translate
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 配置问题的严重程度
+const (
+	IssueError   = "error"   // 配置无法生效，如缺少密钥、地址无效
+	IssueWarning = "warning" // 可能是笔误，如未知的字段
+)
+
+// ConfigIssue 配置文件中的一个问题
+type ConfigIssue struct {
+	Severity string
+	Path     string // 字段路径，如 services[0].app_key
+	Line     int    // 所在行，0 表示未知
+	Message  string
+}
+
+// Location 返回问题所在位置，如 第12行 services[1].appkey
+func (i ConfigIssue) Location() string {
+	switch {
+	case i.Line > 0 && i.Path != "":
+		return fmt.Sprintf("第%d行 %s", i.Line, i.Path)
+	case i.Line > 0:
+		return fmt.Sprintf("第%d行", i.Line)
+	}
+	return i.Path
+}
+
+// String 返回问题说明，如 第12行 services[1].appkey: 未知字段，是否为 app_key
+func (i ConfigIssue) String() string {
+	if location := i.Location(); location != "" {
+		return location + ": " + i.Message
+	}
+	return i.Message
+}
+
+// providerRequiredFields 各服务必须配置的字段
+var providerRequiredFields = map[string][]string{
+	"youdao":  {"app_key", "app_secret"},
+	"deeplx":  {"url"},
+	"deepl":   {"token"},
+	"baidu":   {"app_key", "app_secret"},
+	"tencent": {"app_key", "app_secret"},
+	"azure":   {"app_key"},
+	"dict":    {"path"},
+}
+
+// ValidateConfig 检查配置文件：未知字段、缺少的密钥、无效的地址和文件，dir 为配置文件所在目录
+func ValidateConfig(data []byte, dir string) []ConfigIssue {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return []ConfigIssue{{Severity: IssueError, Message: "YAML 格式错误: " + err.Error()}}
+	}
+	if len(root.Content) == 0 {
+		return []ConfigIssue{{Severity: IssueError, Message: "配置文件为空"}}
+	}
+	doc := root.Content[0]
+
+	var issues []ConfigIssue
+	checkConfigKeys(doc, reflect.TypeOf(Config{}), "", &issues)
+
+	var config Config
+	if err := doc.Decode(&config); err != nil {
+		return append(issues, ConfigIssue{Severity: IssueError, Message: "字段类型错误: " + err.Error()})
+	}
+
+	services := mappingValue(doc, "services")
+	if services == nil || len(config.Services) == 0 {
+		issues = append(issues, ConfigIssue{Severity: IssueError, Path: "services", Message: "未配置翻译服务"})
+	}
+	names := map[string]bool{}
+	for i, item := range config.Services {
+		line := 0
+		if services != nil && i < len(services.Content) {
+			line = services.Content[i].Line
+		}
+		names[item.Name] = true
+		issues = append(issues, validateConfigItem(item, fmt.Sprintf("services[%d]", i), line, dir)...)
+	}
+
+	for _, name := range config.Fallback {
+		if !names[name] {
+			issues = append(issues, ConfigIssue{Severity: IssueError, Path: "fallback", Line: nodeLine(doc, "fallback"), Message: fmt.Sprintf("服务 %q 未配置", name)})
+		}
+	}
+	if !containsString([]string{"", MarkupAuto, MarkupHTML, MarkupXML, MarkupMarkdown, MarkupOff}, config.Markup) {
+		issues = append(issues, ConfigIssue{Severity: IssueError, Path: "markup", Line: nodeLine(doc, "markup"), Message: fmt.Sprintf("无效的值 %q，可选 auto/html/xml/markdown/off", config.Markup)})
+	}
+	if !containsString([]string{"", PrivacyRedact, PrivacyLocal, PrivacyBlock}, config.Privacy.Action) {
+		issues = append(issues, ConfigIssue{Severity: IssueError, Path: "privacy.action", Line: nodeLine(doc, "privacy"), Message: fmt.Sprintf("无效的值 %q，可选 redact/local/block", config.Privacy.Action)})
+	}
+	issues = append(issues, validateHTTPConfig(config.HTTP, "http", nodeLine(doc, "http"), dir)...)
+	if config.Glossary.File != "" {
+		issues = append(issues, validateFile(config.Glossary.File, "glossary.file", nodeLine(doc, "glossary"), dir)...)
+	}
+	return issues
+}
+
+// validateConfigItem 检查单个服务的名称、必填字段、地址和文件
+func validateConfigItem(item ConfigItem, path string, line int, dir string) []ConfigIssue {
+	required, ok := providerRequiredFields[item.Name]
+	if !ok {
+		var known []string
+		for name := range providerRequiredFields {
+			known = append(known, name)
+		}
+		message := fmt.Sprintf("未知的服务 %q", item.Name)
+		if suggestion := closestKey(item.Name, known); suggestion != "" {
+			message += "，是否为 " + suggestion
+		}
+		return []ConfigIssue{{Severity: IssueError, Path: path + ".name", Line: line, Message: message}}
+	}
+
+	var issues []ConfigIssue
+	values := map[string]string{
+		"app_key":    item.AppKey,
+		"app_secret": item.AppSecret,
+		"url":        item.URL,
+		"token":      item.Token,
+		"path":       item.Path,
+	}
+	for _, field := range required {
+		if strings.TrimSpace(values[field]) == "" {
+			issues = append(issues, ConfigIssue{Severity: IssueError, Path: path + "." + field, Line: line, Message: item.Name + " 缺少 " + field + "，服务不会启用"})
+		}
+	}
+
+	issues = append(issues, validateSecretRefs(item, path, line)...)
+	if item.URL != "" && !HasSecretRef(item.URL) {
+		if err := validateURL(item.URL, "http", "https"); err != nil {
+			issues = append(issues, ConfigIssue{Severity: IssueError, Path: path + ".url", Line: line, Message: err.Error()})
+		}
+	}
+	if item.SignType != "" && item.SignType != YoudaoSignV1 && item.SignType != YoudaoSignV3 {
+		issues = append(issues, ConfigIssue{Severity: IssueError, Path: path + ".sign_type", Line: line, Message: fmt.Sprintf("无效的值 %q，可选 v1/v3", item.SignType)})
+	}
+	if item.Path != "" {
+		issues = append(issues, validateFile(item.Path, path+".path", line, dir)...)
+	}
+	return append(issues, validateHTTPConfig(item.HTTP, path, line, dir)...)
+}
+
+// validateSecretRefs 检查引用的格式和环境变量，不读取文件、不执行命令
+func validateSecretRefs(item ConfigItem, path string, line int) []ConfigIssue {
+	var issues []ConfigIssue
+	for field, value := range item.secretFields() {
+		if !HasSecretRef(*value) {
+			continue
+		}
+		refs, ok := parseSecretRefs(*value)
+		if !ok {
+			issues = append(issues, ConfigIssue{Severity: IssueError, Path: path + "." + field, Line: line, Message: "无效的引用，可用 ${ENV:变量名}、${FILE:路径}、${CMD:命令}，命令中的花括号需成对出现"})
+			continue
+		}
+		for _, ref := range refs {
+			if name := strings.TrimSpace(ref.arg); ref.kind == "ENV" && os.Getenv(name) == "" {
+				issues = append(issues, ConfigIssue{Severity: IssueWarning, Path: path + "." + field, Line: line, Message: "环境变量 " + name + " 未设置"})
+			}
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Path < issues[j].Path })
+	return issues
+}
+
+// validateHTTPConfig 检查代理地址和证书文件
+func validateHTTPConfig(config HTTPConfig, path string, line int, dir string) []ConfigIssue {
+	var issues []ConfigIssue
+	if config.Proxy != "" && config.Proxy != "direct" {
+		if err := validateURL(config.Proxy, "http", "https", "socks5", "socks5h"); err != nil {
+			issues = append(issues, ConfigIssue{Severity: IssueError, Path: path + ".proxy", Line: line, Message: err.Error()})
+		}
+	}
+	if config.CABundle != "" {
+		issues = append(issues, validateFile(config.CABundle, path+".ca_bundle", line, dir)...)
+	}
+	return issues
+}
+
+// validateURL 检查地址是否为指定协议的绝对地址
+func validateURL(raw string, schemes ...string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("无效的地址 %q: %v", raw, err)
+	}
+	if !containsString(schemes, strings.ToLower(u.Scheme)) || u.Host == "" {
+		return fmt.Errorf("无效的地址 %q，应以 %s:// 开头并包含主机名", raw, strings.Join(schemes, "://、"))
+	}
+	return nil
+}
+
+// validateFile 检查文件是否存在，相对路径相对于 dir
+func validateFile(path, field string, line int, dir string) []ConfigIssue {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return []ConfigIssue{{Severity: IssueError, Path: field, Line: line, Message: "文件不存在: " + path}}
+	}
+	return nil
+}
+
+// checkConfigKeys 按结构体的 yaml 标签检查映射中的字段，未知字段记为警告
+func checkConfigKeys(node *yaml.Node, t reflect.Type, path string, issues *[]ConfigIssue) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case node.Kind == yaml.SequenceNode && t.Kind() == reflect.Slice:
+		for i, child := range node.Content {
+			checkConfigKeys(child, t.Elem(), fmt.Sprintf("%s[%d]", path, i), issues)
+		}
+	case node.Kind == yaml.MappingNode && t.Kind() == reflect.Struct:
+		fields := yamlFields(t)
+		known := make([]string, 0, len(fields))
+		for key := range fields {
+			known = append(known, key)
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[key.Value]
+			if !ok {
+				message := "未知字段，将被忽略"
+				if suggestion := closestKey(key.Value, known); suggestion != "" {
+					message = "未知字段，是否为 " + suggestion
+				}
+				*issues = append(*issues, ConfigIssue{Severity: IssueWarning, Path: joinConfigPath(path, key.Value), Line: key.Line, Message: message})
+				continue
+			}
+			checkConfigKeys(value, field, joinConfigPath(path, key.Value), issues)
+		}
+	}
+}
+
+// yamlFields 返回结构体可用的 yaml 字段名和类型，inline 字段展开
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if strings.Contains(options, "inline") {
+			for key, value := range yamlFields(field.Type) {
+				fields[key] = value
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// joinConfigPath 拼接字段路径
+func joinConfigPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// mappingValue 返回映射中字段的值节点
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// nodeLine 返回映射中字段所在的行，不存在时返回 0
+func nodeLine(node *yaml.Node, key string) int {
+	if value := mappingValue(node, key); value != nil {
+		return value.Line
+	}
+	return 0
+}
+
+// closestKey 返回与 key 编辑距离不超过 2 的最接近的候选，忽略大小写和 -/_ 的差别
+func closestKey(key string, candidates []string) string {
+	normalize := strings.NewReplacer("-", "", "_", "").Replace
+	best, bestDistance := "", 3
+	for _, candidate := range candidates {
+		distance := editDistance(normalize(strings.ToLower(key)), normalize(candidate))
+		if distance < bestDistance || (distance == bestDistance && candidate < best) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// editDistance 计算两个字符串的编辑距离
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+// minInt 返回最小值
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
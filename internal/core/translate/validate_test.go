@@ -0,0 +1,156 @@
+package translate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ca.pem"), []byte("pem"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TRANSLATE_TEST_SET", "value")
+	t.Setenv("TRANSLATE_TEST_UNSET", "")
+
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{
+			name: "有效配置",
+			config: `services:
+  - name: deeplx
+    url: https://deeplx.example.com/translate
+`,
+			want: nil,
+		},
+		{
+			name: "未知字段给出建议",
+			config: `services:
+  - name: youdao
+    appkey: key
+    app_secret: secret
+`,
+			want: []string{
+				"第3行 services[0].appkey: 未知字段，是否为 app_key",
+				"第2行 services[0].app_key: youdao 缺少 app_key，服务不会启用",
+			},
+		},
+		{
+			name: "未知服务给出建议",
+			config: `services:
+  - name: youdo
+`,
+			want: []string{`第2行 services[0].name: 未知的服务 "youdo"，是否为 youdao`},
+		},
+		{
+			name:   "未配置服务",
+			config: "timeout: 3\n",
+			want:   []string{"services: 未配置翻译服务"},
+		},
+		{
+			name: "缺少必填字段",
+			config: `services:
+  - name: baidu
+    app_key: key
+`,
+			want: []string{"第2行 services[0].app_secret: baidu 缺少 app_secret，服务不会启用"},
+		},
+		{
+			name: "无效的代理协议",
+			config: `services:
+  - name: deepl
+    token: token
+    proxy: ftp://proxy.local:21
+`,
+			want: []string{`第2行 services[0].proxy: 无效的地址 "ftp://proxy.local:21"，应以 http://、https://、socks5://、socks5h:// 开头并包含主机名`},
+		},
+		{
+			name: "socks5h代理和相对路径的证书",
+			config: `services:
+  - name: deepl
+    token: token
+http:
+  proxy: socks5h://127.0.0.1:1080
+  ca_bundle: ca.pem
+`,
+			want: nil,
+		},
+		{
+			name: "证书文件不存在",
+			config: `services:
+  - name: deepl
+    token: token
+http:
+  ca_bundle: missing.pem
+`,
+			want: []string{"第5行 http.ca_bundle: 文件不存在: " + filepath.Join(dir, "missing.pem")},
+		},
+		{
+			name: "密钥引用",
+			config: `services:
+  - name: youdao
+    app_key: ${ENV:TRANSLATE_TEST_SET}
+    app_secret: ${ENV:TRANSLATE_TEST_UNSET}
+  - name: deepl
+    token: ${VAULT:deepl}
+`,
+			want: []string{
+				"第2行 services[0].app_secret: 环境变量 TRANSLATE_TEST_UNSET 未设置",
+				"第5行 services[1].token: 无效的引用，可用 ${ENV:变量名}、${FILE:路径}、${CMD:命令}，命令中的花括号需成对出现",
+			},
+		},
+		{
+			name: "fallback引用未配置的服务",
+			config: `services:
+  - name: deepl
+    token: token
+fallback: [deepl, youdao]
+`,
+			want: []string{`第4行 fallback: 服务 "youdao" 未配置`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, issue := range ValidateConfig([]byte(tt.config), dir) {
+				got = append(got, issue.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateConfig() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestValidateConfigYAMLError(t *testing.T) {
+	issues := ValidateConfig([]byte("services:\n  - name: deepl\n   token: x\n"), "")
+	if len(issues) != 1 || issues[0].Severity != IssueError || !strings.HasPrefix(issues[0].Message, "YAML 格式错误") {
+		t.Errorf("ValidateConfig() = %v, want one YAML error", issues)
+	}
+}
+
+func TestClosestKey(t *testing.T) {
+	candidates := []string{"app_key", "app_secret", "sign_type", "timeout"}
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"appkey", "app_key"},
+		{"App-Secret", "app_secret"},
+		{"signtyp", "sign_type"},
+		{"region_name", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := closestKey(tt.key, candidates); got != tt.want {
+				t.Errorf("closestKey(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package translate
+
+import (
+	"strings"
+)
+
+// youdaoWebPhraseLimit 展示的网络短语数量上限
+const youdaoWebPhraseLimit = 3
+
+// YoudaoBasic 有道词典基本释义
+type YoudaoBasic struct {
+	Phonetic   string           `json:"phonetic,omitempty"`
+	UkPhonetic string           `json:"uk-phonetic,omitempty"`
+	UsPhonetic string           `json:"us-phonetic,omitempty"`
+	UkSpeech   string           `json:"uk-speech,omitempty"`
+	UsSpeech   string           `json:"us-speech,omitempty"`
+	Explains   []string         `json:"explains,omitempty"`
+	Wfs        []YoudaoWordForm `json:"wfs,omitempty"`
+}
+
+// YoudaoWordForm 有道词典词形变化，如复数、过去式
+type YoudaoWordForm struct {
+	Wf struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"wf"`
+}
+
+// YoudaoWeb 有道词典网络释义
+type YoudaoWeb struct {
+	Key   string   `json:"key"`
+	Value []string `json:"value"`
+}
+
+// Languages 从 l 字段(如 en2zh-CHS)解析源语言和目标语言
+func (r *YoudaoTranslationResult) Languages() (string, string) {
+	parts := strings.SplitN(r.L, "2", 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
+
+// Speech 返回单词的发音，优先美式发音
+func (b *YoudaoBasic) Speech(word string) *Speech {
+	if b.UsSpeech != "" {
+		return &Speech{Text: word, Voice: "us", URL: b.UsSpeech}
+	}
+	if b.UkSpeech != "" {
+		return &Speech{Text: word, Voice: "uk", URL: b.UkSpeech}
+	}
+	return nil
+}
+
+// Phonetics 返回格式化的音标，如 英 [həˈləʊ]  美 [heˈloʊ]
+func (b *YoudaoBasic) Phonetics() string {
+	var parts []string
+	if b.UkPhonetic != "" {
+		parts = append(parts, "英 ["+b.UkPhonetic+"]")
+	}
+	if b.UsPhonetic != "" && b.UsPhonetic != b.UkPhonetic {
+		parts = append(parts, "美 ["+b.UsPhonetic+"]")
+	}
+	if len(parts) == 0 && b.Phonetic != "" {
+		parts = append(parts, "["+b.Phonetic+"]")
+	}
+	return strings.Join(parts, "  ")
+}
+
+// WordForms 返回格式化的词形变化，如 复数: hellos  过去式: helloed
+func (b *YoudaoBasic) WordForms() string {
+	var parts []string
+	for _, wfs := range b.Wfs {
+		if wfs.Wf.Name != "" && wfs.Wf.Value != "" {
+			parts = append(parts, wfs.Wf.Name+": "+wfs.Wf.Value)
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+// DictionaryResults 将词典的音标、各词性释义、词形变化和网络短语转换为独立的结果项
+func (r *YoudaoTranslationResult) DictionaryResults(query, reviewUrl string) []TranslationResult {
+	var results []TranslationResult
+
+	if r.Basic != nil {
+		if phonetics := r.Basic.Phonetics(); phonetics != "" {
+			results = append(results, TranslationResult{
+				Title:    phonetics,
+				Subtitle: "有道词典 音标: " + query,
+				Value:    phonetics,
+				Provider: ProviderYoudao,
+				Url:      &reviewUrl,
+				Speech:   r.Basic.Speech(query),
+			})
+		}
+
+		for _, explain := range r.Basic.Explains {
+			results = append(results, TranslationResult{
+				Title:    explain,
+				Subtitle: "有道词典 释义: " + query,
+				Value:    explain,
+				Provider: ProviderYoudao,
+				Url:      &reviewUrl,
+			})
+		}
+
+		if wordForms := r.Basic.WordForms(); wordForms != "" {
+			results = append(results, TranslationResult{
+				Title:    wordForms,
+				Subtitle: "有道词典 词形: " + query,
+				Value:    wordForms,
+				Provider: ProviderYoudao,
+				Url:      &reviewUrl,
+			})
+		}
+	}
+
+	for i, web := range r.Web {
+		if i >= youdaoWebPhraseLimit {
+			break
+		}
+		meanings := strings.Join(web.Value, "; ")
+		results = append(results, TranslationResult{
+			Title:    web.Key + ": " + meanings,
+			Subtitle: "有道词典 网络短语: " + query,
+			Value:    meanings,
+			Provider: ProviderYoudao,
+			Url:      &reviewUrl,
+		})
+	}
+
+	return results
+}
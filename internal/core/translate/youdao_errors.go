@@ -0,0 +1,60 @@
+package translate
+
+// youdaoError 有道翻译错误码说明及类型
+type youdaoError struct {
+	message string
+	kind    ErrorKind
+}
+
+// youdaoErrors 有道翻译错误码
+// 参考: https://ai.youdao.com/DOCSIRMA/html/trans/api/wbfy/index.html
+var youdaoErrors = map[string]youdaoError{
+	"101":  {"缺少必填的参数", ErrorUnknown},
+	"102":  {"不支持的语言类型", ErrorUnsupportedLanguage},
+	"103":  {"翻译文本过长", ErrorUnknown},
+	"104":  {"不支持的API类型", ErrorUnknown},
+	"105":  {"不支持的签名类型", ErrorAuth},
+	"106":  {"不支持的响应类型", ErrorUnknown},
+	"107":  {"不支持的传输加密类型", ErrorUnknown},
+	"108":  {"应用ID无效，请检查app_key", ErrorAuth},
+	"109":  {"batchLog格式不正确", ErrorUnknown},
+	"110":  {"无相关服务的有效应用，请在控制台为应用绑定文本翻译服务", ErrorAuth},
+	"111":  {"开发者账号无效", ErrorAuth},
+	"112":  {"请求服务无效", ErrorUnknown},
+	"113":  {"翻译文本不能为空", ErrorUnknown},
+	"114":  {"不支持的图片传输方式", ErrorUnknown},
+	"116":  {"strict字段取值无效", ErrorUnknown},
+	"201":  {"解密失败", ErrorAuth},
+	"202":  {"签名检验失败，请检查app_key和app_secret", ErrorAuth},
+	"203":  {"访问IP地址不在可访问IP列表", ErrorAuth},
+	"205":  {"请求的接口与应用的平台类型不一致", ErrorAuth},
+	"206":  {"时间戳无效导致签名校验失败", ErrorAuth},
+	"207":  {"重放请求", ErrorAuth},
+	"301":  {"辞典查询失败", ErrorBadResponse},
+	"302":  {"翻译查询失败", ErrorBadResponse},
+	"303":  {"服务端的其它异常", ErrorBadResponse},
+	"304":  {"会话闲置太久超时", ErrorNetwork},
+	"308":  {"rejectFallback参数错误", ErrorUnknown},
+	"309":  {"domain参数错误", ErrorUnknown},
+	"310":  {"未开通领域翻译服务", ErrorAuth},
+	"401":  {"账户已经欠费，请充值", ErrorQuota},
+	"402":  {"offlinesdk不可用", ErrorUnknown},
+	"411":  {"访问频率受限，请稍后访问", ErrorRateLimited},
+	"412":  {"长请求过于频繁，请稍后访问", ErrorRateLimited},
+	"1412": {"超过最大识别字节数", ErrorUnknown},
+	"2005": {"ext参数不对", ErrorUnknown},
+	"2006": {"不支持的voice", ErrorUnknown},
+	"3412": {"语音合成过于频繁，请稍后访问", ErrorRateLimited},
+	"4412": {"语音识别过于频繁，请稍后访问", ErrorRateLimited},
+	"9001": {"不支持的语言类型", ErrorUnsupportedLanguage},
+	"9002": {"不支持的语言类型", ErrorUnsupportedLanguage},
+}
+
+// YoudaoError 根据有道翻译错误码创建错误
+func YoudaoError(code string) *Error {
+	e, ok := youdaoErrors[code]
+	if !ok {
+		e = youdaoError{"未知错误", ErrorUnknown}
+	}
+	return NewError(ProviderYoudao, e.kind, code, e.message)
+}
@@ -0,0 +1,70 @@
+package translate
+
+import "testing"
+
+// 签名测试使用的固定参数，期望值由 hashlib 独立计算
+const (
+	youdaoTestAppKey    = "appkey123"
+	youdaoTestAppSecret = "secret456"
+	youdaoTestSalt      = "5b7f3d2e-1c4a-4e8b-9f10-2a3b4c5d6e7f"
+	youdaoTestCurtime   = "1700000000"
+)
+
+func TestYoudaoTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "短文本不截断", query: "hello", want: "hello"},
+		{name: "正好20个字符不截断", query: "abcdefghijklmnopqrst", want: "abcdefghijklmnopqrst"},
+		{name: "21个字符截断", query: "abcdefghijklmnopqrstu", want: "abcdefghij21lmnopqrstu"},
+		{name: "中文按字符而不是字节截断", query: "这是一段超过二十个字符的中文句子用于测试有道签名截断", want: "这是一段超过二十个字26用于测试有道签名截断"},
+		{name: "20个中文字符不截断", query: "一二三四五六七八九十一二三四五六七八九十", want: "一二三四五六七八九十一二三四五六七八九十"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := YoudaoTruncate(tt.query); got != tt.want {
+				t.Errorf("YoudaoTruncate(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestYoudaoSignV3Digest(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"hello", "01a0990ab8a18a14b6b57b0c6ceb8daf275d444245c22c71e5fae262187aef09"},
+		{"abcdefghijklmnopqrstu", "149896a6c4c6cecb06427473b865ea5c9ff913b54664ea1250bf0f1303977f54"},
+		{"这是一段超过二十个字符的中文句子用于测试有道签名截断", "361b47c664bbdfb6c5e5212b5e1ba87c1f5b4f0a8a46bf5cc057bf3f7ae06b2c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := YoudaoSignV3Digest(youdaoTestAppKey, tt.query, youdaoTestSalt, youdaoTestCurtime, youdaoTestAppSecret)
+			if got != tt.want {
+				t.Errorf("YoudaoSignV3Digest(%q) = %s, want %s", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestYoudaoSignV1Digest(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"hello", "8913b5106479dddea9a21b44c8fa4b1c"},
+		// v1 签名使用完整查询，不截断
+		{"你好世界", "ecd674fab30f220752d825faa2c747a7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := YoudaoSignV1Digest(youdaoTestAppKey, tt.query, youdaoTestSalt, youdaoTestAppSecret)
+			if got != tt.want {
+				t.Errorf("YoudaoSignV1Digest(%q) = %s, want %s", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package alfred
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// IsAlfred 检查当前进程是否由 Alfred 调用
+func IsAlfred() bool {
+	return os.Getenv("alfred_version") != ""
+}
+
+// CacheDir 返回工作流缓存目录，优先使用 Alfred 提供的 alfred_workflow_cache
+// 不在 Alfred 中运行时使用系统缓存目录下的 AlfredWorkflows/name
+func CacheDir(name string) string {
+	dir := os.Getenv("alfred_workflow_cache")
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			base = os.TempDir()
+		}
+		dir = filepath.Join(base, "AlfredWorkflows", name)
+	}
+	_ = os.MkdirAll(dir, 0o755)
+	return dir
+}
+
+// DataDir 返回工作流数据目录，优先使用 Alfred 提供的 alfred_workflow_data
+// 不在 Alfred 中运行时使用系统配置目录下的 AlfredWorkflows/name
+func DataDir(name string) string {
+	dir := os.Getenv("alfred_workflow_data")
+	if dir == "" {
+		base, err := os.UserConfigDir()
+		if err != nil {
+			base = os.TempDir()
+		}
+		dir = filepath.Join(base, "AlfredWorkflows", name)
+	}
+	_ = os.MkdirAll(dir, 0o755)
+	return dir
+}
@@ -5,8 +5,20 @@ type AlfredItem struct {
 	Title        string `json:"title"`
 	Subtitle     string `json:"subtitle"`
 	Arg          string `json:"arg,omitempty"`
+	Valid        *bool  `json:"valid,omitempty"`        // 为 false 时结果项不可选中
 	Icon         string `json:"icon,omitempty"`         // 每行显示的 icon
 	Quicklookurl string `json:"quicklookurl,omitempty"` // 快速预览的URL
+
+	Mods      map[string]AlfredMod `json:"mods,omitempty"`      // 按下修饰键(cmd/alt/ctrl/shift)时的替代行为
+	Variables map[string]string    `json:"variables,omitempty"` // 传递给后续动作的变量
+}
+
+// AlfredMod 表示按下修饰键时结果项的替代行为
+type AlfredMod struct {
+	Valid     *bool             `json:"valid,omitempty"`
+	Arg       string            `json:"arg,omitempty"`
+	Subtitle  string            `json:"subtitle,omitempty"`
+	Variables map[string]string `json:"variables,omitempty"`
 }
 
 // GetTitle 返回项目标题
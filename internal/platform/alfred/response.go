@@ -7,7 +7,9 @@ import (
 
 // AlfredResponse 表示 Alfred Workflow 的响应
 type AlfredResponse struct {
-	Items []AlfredItem `json:"items"`
+	Rerun     float64           `json:"rerun,omitempty"`     // 间隔多少秒(0.1~5.0)后 Alfred 重新执行脚本
+	Variables map[string]string `json:"variables,omitempty"` // 重新执行时以环境变量传回脚本
+	Items     []AlfredItem      `json:"items"`
 }
 
 // NewResponse 创建一个新的 AlfredResponse